@@ -1,5 +1,10 @@
 module github.com/jlauinger/go-safer
 
-go 1.14
+go 1.22.0
 
-require golang.org/x/tools v0.0.0-20200504193531-9bfbc385433f
+require golang.org/x/tools v0.30.0
+
+require (
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+)
@@ -1,12 +1,26 @@
 package main
 
 import (
+	"github.com/jlauinger/go-safer/passes/alignment"
+	"github.com/jlauinger/go-safer/passes/atomicalign"
+	"github.com/jlauinger/go-safer/passes/endianness"
+	"github.com/jlauinger/go-safer/passes/headermigration"
+	"github.com/jlauinger/go-safer/passes/keepalive"
+	"github.com/jlauinger/go-safer/passes/linkname"
+	"github.com/jlauinger/go-safer/passes/localescape"
+	"github.com/jlauinger/go-safer/passes/runtimelayout"
 	"github.com/jlauinger/go-safer/passes/sliceheader"
+	"github.com/jlauinger/go-safer/passes/stringcast"
+	"github.com/jlauinger/go-safer/passes/stringmutation"
 	"github.com/jlauinger/go-safer/passes/structcast"
+	"github.com/jlauinger/go-safer/passes/unsafepointer"
 	"golang.org/x/tools/go/analysis/multichecker"
 )
 
 func main() {
 	// invoke go vet main function with go-safer analyzers
-	multichecker.Main(sliceheader.Analyzer, structcast.Analyzer)
+	multichecker.Main(sliceheader.Analyzer, structcast.Analyzer, stringcast.Analyzer, stringmutation.Analyzer,
+		unsafepointer.Analyzer, localescape.Analyzer, keepalive.Analyzer, headermigration.Analyzer,
+		alignment.Analyzer, endianness.Analyzer, atomicalign.Analyzer,
+		runtimelayout.Analyzer, linkname.Analyzer)
 }
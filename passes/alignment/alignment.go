@@ -0,0 +1,395 @@
+package alignment
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/jlauinger/go-safer/passes/internal/layout"
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+)
+
+// Analyzer is a golang.org/x/tools/go/analysis style linter pass.
+// Use this with the Vet-style infrastructure.
+var Analyzer = &analysis.Analyzer{
+	Name:             "alignment",
+	Doc:              "reports casts from byte buffer elements or unsafe.Add results to pointers that may be misaligned",
+	Run:              run,
+	Requires:         []*analysis.Analyzer{inspect.Analyzer},
+	RunDespiteErrors: true,
+}
+
+// strictArchitectures are the GOARCH values where loads and stores must be aligned to the alignment of their type
+var strictArchitectures = []string{"arm", "loong64", "mips", "mips64", "mips64le", "mipsle", "riscv64"}
+
+// atomicArchitectures are the GOARCH values where 64-bit atomic operations need 8-byte alignment, in addition to the
+// strict architectures. Plain loads and stores may be misaligned there, but the atomic operations panic or fault
+var atomicArchitectures = []string{"386", "arm64", "ppc64", "ppc64le", "s390x"}
+
+// allocatorAlignment is the alignment that the memory allocator guarantees for make and new, unless the object comes
+// from the tiny allocator
+const allocatorAlignment = 8
+
+// tinyAllocationSize is the size below which objects without pointers are packed together by the tiny allocator, which
+// aligns them according to their size
+const tinyAllocationSize = 16
+
+/**
+ * run is the entry point to the analysis pass
+ */
+func run(pass *analysis.Pass) (interface{}, error) {
+	// get results from required inspect analyzer
+	inspectResult := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	// remember the values assigned to variables, to find out whether a buffer is a fresh allocation
+	values := assignedValues(inspectResult, pass)
+
+	inspectResult.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		node := n.(*ast.CallExpr)
+
+		// find casts of the form (*T)(unsafe.Pointer(...))
+		target, ok := pass.TypesInfo.Types[node.Fun]
+		if !ok || !target.IsType() || len(node.Args) != 1 {
+			return true
+		}
+		pointer, ok := target.Type.Underlying().(*types.Pointer)
+		if !ok || !resolve.IsUnsafePointerType(pass.TypesInfo.TypeOf(node.Args[0])) {
+			return true
+		}
+
+		// the alignment of a type parameter is not known before it is instantiated
+		if resolve.ContainsTypeParam(pointer.Elem()) {
+			return true
+		}
+
+		// the source must be the address of a byte buffer element or the result of unsafe.Add
+		source, description := addressSource(node.Args[0], pass)
+		if source == nil {
+			return true
+		}
+
+		// 64-bit atomic operations need 8-byte alignment on more architectures
+		architectures := strictArchitectures
+		atomic := isAtomic64Argument(node, stack, pass)
+		if atomic {
+			architectures = append(append([]string{}, atomicArchitectures...), strictArchitectures...)
+		}
+
+		// compare the alignment that the target type needs with the alignment that the address is known to have
+		needed := make(map[int64][]string)
+		known := int64(0)
+		for _, arch := range architectures {
+			sizes := types.SizesFor("gc", arch)
+			required := sizes.Alignof(pointer.Elem())
+			if atomic {
+				required = 8
+			}
+			actual := addressAlignment(source, values, sizes, pass)
+			if required > actual {
+				needed[required] = append(needed[required], arch)
+				if known == 0 || actual < known {
+					known = actual
+				}
+			}
+		}
+		if len(needed) == 0 {
+			return true
+		}
+
+		access := ""
+		if atomic {
+			access = " for a 64-bit atomic operation"
+		}
+		pass.Reportf(node.Pos(), "cast of %s to %s%s may be misaligned: the address is only known to be %d-byte "+
+			"aligned, but %s needs %s", description, types.TypeString(pointer, types.RelativeTo(pass.Pkg)), access,
+			known, types.TypeString(pointer.Elem(), types.RelativeTo(pass.Pkg)), describeNeeded(needed))
+		return true
+	})
+
+	return nil, nil
+}
+
+/**
+ * finds the expression that an unsafe.Pointer is derived from, if it is the address of a byte buffer element or the
+ * result of unsafe.Add, together with a description for messages. Returns nil for other pointers
+ */
+func addressSource(expr ast.Expr, pass *analysis.Pass) (ast.Expr, string) {
+	expr = ast.Unparen(expr)
+	if call, ok := expr.(*ast.CallExpr); ok {
+		if resolve.IsUnsafePointerConversion(pass.TypesInfo, call) {
+			return addressSource(call.Args[0], pass)
+		}
+		if resolve.IsPackageObject(pass.TypesInfo, call.Fun, "unsafe", "Add") && len(call.Args) == 2 {
+			return call, "an unsafe.Add result"
+		}
+	}
+	if _, _, ok := layout.ByteElementAddress(pass.TypesInfo, expr); ok {
+		return expr, "a byte buffer element"
+	}
+	return nil, ""
+}
+
+/**
+ * computes the alignment that an address is known to have on an architecture
+ */
+func addressAlignment(expr ast.Expr, values map[types.Object][]ast.Expr, sizes types.Sizes,
+	pass *analysis.Pass) int64 {
+	expr = ast.Unparen(expr)
+
+	// the address of a buffer element is aligned like the buffer, moved by the index
+	if buffer, index, ok := layout.ByteElementAddress(pass.TypesInfo, expr); ok {
+		return offsetAlignment(bufferAlignment(buffer, values, sizes, pass), index, pass)
+	}
+
+	if call, ok := expr.(*ast.CallExpr); ok {
+		// unsafe.Add moves the alignment of its pointer by the offset
+		if resolve.IsPackageObject(pass.TypesInfo, call.Fun, "unsafe", "Add") && len(call.Args) == 2 {
+			return offsetAlignment(addressAlignment(call.Args[0], values, sizes, pass), call.Args[1], pass)
+		}
+		if resolve.IsUnsafePointerConversion(pass.TypesInfo, call) {
+			return addressAlignment(call.Args[0], values, sizes, pass)
+		}
+	}
+
+	// other typed pointers are aligned like the type they point to
+	t := pass.TypesInfo.TypeOf(expr)
+	if t == nil {
+		return 1
+	}
+	if pointer, ok := t.Underlying().(*types.Pointer); ok && !resolve.ContainsTypeParam(pointer.Elem()) {
+		return sizes.Alignof(pointer.Elem())
+	}
+	return 1
+}
+
+/**
+ * computes the alignment of the first element of a byte buffer. Only buffers that are always a fresh allocation with
+ * make or new are known to be aligned
+ */
+func bufferAlignment(buffer ast.Expr, values map[types.Object][]ast.Expr, sizes types.Sizes,
+	pass *analysis.Pass) int64 {
+	if alignment := allocationAlignment(buffer, sizes, pass); alignment != 0 {
+		return alignment
+	}
+	ident, ok := ast.Unparen(buffer).(*ast.Ident)
+	if !ok {
+		return 1
+	}
+	variable, ok := pass.TypesInfo.ObjectOf(ident).(*types.Var)
+	if !ok || variable.Parent() == nil || variable.Parent() == pass.Pkg.Scope() || len(values[variable]) == 0 {
+		// parameters, fields and package-level variables can be set anywhere
+		return 1
+	}
+	alignment := int64(allocatorAlignment)
+	for _, value := range values[variable] {
+		valueAlignment := allocationAlignment(value, sizes, pass)
+		if valueAlignment == 0 {
+			return 1
+		}
+		alignment = min(alignment, valueAlignment)
+	}
+	return alignment
+}
+
+/**
+ * computes the alignment that the memory allocator guarantees for a call to make or new. Small objects may come from
+ * the tiny allocator, which aligns them to 8, 4 or 2 bytes if their size is a multiple of that. Allocations of an
+ * unknown size are not known to be aligned. It returns 0 if the expression is not an allocation
+ */
+func allocationAlignment(expr ast.Expr, sizes types.Sizes, pass *analysis.Pass) int64 {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return 0
+	}
+	builtin, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Builtin)
+	if !ok || (builtin.Name() != "make" && builtin.Name() != "new") {
+		return 0
+	}
+	size, ok := allocationSize(call, builtin.Name() == "new", sizes, pass)
+	switch {
+	case !ok:
+		return 1
+	case size >= tinyAllocationSize || size%8 == 0:
+		return allocatorAlignment
+	case size%4 == 0:
+		return 4
+	case size%2 == 0:
+		return 2
+	}
+	return 1
+}
+
+/**
+ * computes the size in bytes that a call to make or new allocates, if it is constant. For slices, this is the capacity
+ * times the size of the elements
+ */
+func allocationSize(call *ast.CallExpr, isNew bool, sizes types.Sizes, pass *analysis.Pass) (int64, bool) {
+	if len(call.Args) == 0 {
+		return 0, false
+	}
+	t := pass.TypesInfo.TypeOf(call.Args[0])
+	if t == nil || resolve.ContainsTypeParam(t) {
+		return 0, false
+	}
+	if isNew {
+		return sizes.Sizeof(t), true
+	}
+	slice, ok := t.Underlying().(*types.Slice)
+	if !ok || len(call.Args) < 2 {
+		return 0, false
+	}
+	// the capacity is the last argument, which is the length if there is no capacity
+	value := pass.TypesInfo.Types[call.Args[len(call.Args)-1]].Value
+	if value == nil {
+		return 0, false
+	}
+	count, exact := constant.Int64Val(constant.ToInt(value))
+	if !exact {
+		return 0, false
+	}
+	return count * sizes.Sizeof(slice.Elem()), true
+}
+
+/**
+ * computes the alignment of an address that is moved by an offset from an address with the given alignment
+ */
+func offsetAlignment(base int64, offset ast.Expr, pass *analysis.Pass) int64 {
+	factor := knownFactor(offset, pass)
+	if factor == 0 {
+		// the offset is zero
+		return base
+	}
+	return layout.GCD(base, factor)
+}
+
+/**
+ * computes a number that an integer expression is known to be a multiple of. Zero means the expression is zero
+ */
+func knownFactor(expr ast.Expr, pass *analysis.Pass) int64 {
+	expr = ast.Unparen(expr)
+	if value := pass.TypesInfo.Types[expr].Value; value != nil {
+		v, exact := constant.Int64Val(constant.ToInt(value))
+		if !exact {
+			return 1
+		}
+		if v < 0 {
+			return -v
+		}
+		return v
+	}
+
+	switch e := expr.(type) {
+	case *ast.BinaryExpr:
+		x, y := knownFactor(e.X, pass), knownFactor(e.Y, pass)
+		switch e.Op {
+		case token.MUL:
+			return x * y
+		case token.ADD, token.SUB:
+			return layout.GCD(x, y)
+		case token.SHL:
+			if shift := pass.TypesInfo.Types[e.Y].Value; shift != nil {
+				if s, exact := constant.Int64Val(constant.ToInt(shift)); exact && s >= 0 && s < 32 {
+					return x << s
+				}
+			}
+		case token.AND:
+			// masking with ^(n-1) clears the low bits
+			if mask := pass.TypesInfo.Types[e.Y].Value; mask != nil {
+				if m, exact := constant.Int64Val(constant.ToInt(mask)); exact && m != 0 {
+					return layout.GCD(x, m&-m)
+				}
+			}
+		}
+	case *ast.CallExpr:
+		// conversions between integer types keep the factor
+		if target, ok := pass.TypesInfo.Types[e.Fun]; ok && target.IsType() && len(e.Args) == 1 {
+			return knownFactor(e.Args[0], pass)
+		}
+	}
+	return 1
+}
+
+/**
+ * checks whether a cast is passed directly to a 64-bit function of the sync/atomic package
+ */
+func isAtomic64Argument(call *ast.CallExpr, stack []ast.Node, pass *analysis.Pass) bool {
+	for i := len(stack) - 2; i >= 0; i-- {
+		if _, ok := stack[i].(*ast.ParenExpr); ok {
+			continue
+		}
+		parent, ok := stack[i].(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		callee, ok := typeutil.Callee(pass.TypesInfo, parent).(*types.Func)
+		return ok && callee.Pkg() != nil && callee.Pkg().Path() == "sync/atomic" && strings.HasSuffix(callee.Name(), "64")
+	}
+	return false
+}
+
+/**
+ * describes which alignment is needed on which architectures
+ */
+func describeNeeded(needed map[int64][]string) string {
+	alignments := make([]int64, 0, len(needed))
+	for alignment := range needed {
+		alignments = append(alignments, alignment)
+	}
+	sort.Slice(alignments, func(i, j int) bool { return alignments[i] < alignments[j] })
+
+	parts := make([]string, 0, len(alignments))
+	for _, alignment := range alignments {
+		archs := needed[alignment]
+		sort.Strings(archs)
+		parts = append(parts, fmt.Sprintf("%d-byte alignment on %s", alignment, strings.Join(archs, ", ")))
+	}
+	return strings.Join(parts, " and ")
+}
+
+/**
+ * collects the values assigned to variables in the package
+ */
+func assignedValues(inspectResult *inspector.Inspector, pass *analysis.Pass) map[types.Object][]ast.Expr {
+	values := make(map[types.Object][]ast.Expr)
+	record := func(lhs ast.Expr, rhs ast.Expr) {
+		if ident, ok := ast.Unparen(lhs).(*ast.Ident); ok {
+			if object := pass.TypesInfo.ObjectOf(ident); object != nil {
+				values[object] = append(values[object], rhs)
+			}
+		}
+	}
+	inspectResult.Preorder([]ast.Node{(*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil)}, func(n ast.Node) {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			if len(node.Lhs) == len(node.Rhs) {
+				for i := range node.Lhs {
+					record(node.Lhs[i], node.Rhs[i])
+				}
+			} else {
+				// values from multiple results are unknown
+				for _, lhs := range node.Lhs {
+					record(lhs, nil)
+				}
+			}
+		case *ast.ValueSpec:
+			for i, name := range node.Names {
+				if i < len(node.Values) {
+					record(name, node.Values[i])
+				}
+			}
+		}
+	})
+	return values
+}
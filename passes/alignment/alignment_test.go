@@ -0,0 +1,18 @@
+package alignment_test
+
+import (
+	"github.com/jlauinger/go-safer/passes/alignment"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"testing"
+)
+
+func Test(t *testing.T) {
+	// use go vet infrastructure testing and supply annotated code examples
+	testdata := analysistest.TestData()
+	testPackages := []string{
+		"bad/unaligned_cast",
+
+		"good/aligned_cast",
+	}
+	analysistest.Run(t, testdata, alignment.Analyzer, testPackages...)
+}
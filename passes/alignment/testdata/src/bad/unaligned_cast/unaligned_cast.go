@@ -0,0 +1,63 @@
+package unaligned_cast
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+type record struct {
+	id    uint32
+	flags uint16
+}
+
+func ConstantOffset(buf []byte) uint64 {
+	return *(*uint64)(unsafe.Pointer(&buf[0])) // want "cast of a byte buffer element to \\*uint64 may be misaligned: the address is only known to be 1-byte aligned, but uint64 needs 4-byte alignment on arm, mips, mipsle and 8-byte alignment on loong64, mips64, mips64le, riscv64"
+}
+
+func OddOffset() uint32 {
+	buf := make([]byte, 64)
+	return *(*uint32)(unsafe.Pointer(&buf[6])) // want "cast of a byte buffer element to \\*uint32 may be misaligned: the address is only known to be 2-byte aligned, but uint32 needs 4-byte alignment on arm, loong64, mips, mips64, mips64le, mipsle, riscv64"
+}
+
+func VariableOffset(buf *[64]byte, i int) *record {
+	return (*record)(unsafe.Pointer(&buf[i*2])) // want "cast of a byte buffer element to \\*record may be misaligned: the address is only known to be 1-byte aligned, but record needs 4-byte alignment on arm, loong64, mips, mips64, mips64le, mipsle, riscv64"
+}
+
+func LocalArray() uint16 {
+	var buf [8]byte
+	return *(*uint16)(unsafe.Pointer(&buf[2])) // want "cast of a byte buffer element to \\*uint16 may be misaligned: the address is only known to be 1-byte aligned, but uint16 needs 2-byte alignment on arm, loong64, mips, mips64, mips64le, mipsle, riscv64"
+}
+
+func UnsafeAdd(p *uint64) *uint32 {
+	return (*uint32)(unsafe.Add(unsafe.Pointer(p), 2)) // want "cast of an unsafe.Add result to \\*uint32 may be misaligned: the address is only known to be 2-byte aligned, but uint32 needs 4-byte alignment on arm, loong64, mips, mips64, mips64le, mipsle, riscv64"
+}
+
+func AtomicCounter() uint64 {
+	buf := make([]byte, 64)
+	return atomic.AddUint64((*uint64)(unsafe.Pointer(&buf[4])), 1) // want "cast of a byte buffer element to \\*uint64 for a 64-bit atomic operation may be misaligned: the address is only known to be 4-byte aligned, but uint64 needs 8-byte alignment on 386, arm, arm64, loong64, mips, mips64, mips64le, mipsle, ppc64, ppc64le, riscv64, s390x"
+}
+
+func AtomicOddOffset(buf []byte) uint64 {
+	return atomic.LoadUint64((*uint64)(unsafe.Pointer(&buf[1]))) // want "cast of a byte buffer element to \\*uint64 for a 64-bit atomic operation may be misaligned: the address is only known to be 1-byte aligned, but uint64 needs 8-byte alignment on 386, arm, arm64, loong64, mips, mips64, mips64le, mipsle, ppc64, ppc64le, riscv64, s390x"
+}
+
+func ShiftGeneric[T any](p *T) *uint32 {
+	// T might be a single byte, so nothing is known about the alignment of p
+	return (*uint32)(unsafe.Add(unsafe.Pointer(p), 4)) // want "cast of an unsafe.Add result to \\*uint32 may be misaligned: the address is only known to be 1-byte aligned"
+}
+
+func ShortBuffer() uint64 {
+	// buffers of less than 16 bytes come from the tiny allocator, which only aligns them according to their size
+	buf := make([]byte, 12)
+	return *(*uint64)(unsafe.Pointer(&buf[0])) // want "cast of a byte buffer element to \\*uint64 may be misaligned: the address is only known to be 4-byte aligned, but uint64 needs 8-byte alignment on loong64, mips64, mips64le, riscv64"
+}
+
+func OddBuffer() uint16 {
+	buf := make([]byte, 7)
+	return *(*uint16)(unsafe.Pointer(&buf[0])) // want "cast of a byte buffer element to \\*uint16 may be misaligned: the address is only known to be 1-byte aligned"
+}
+
+func UnknownSize(n int) uint32 {
+	buf := make([]byte, n)
+	return *(*uint32)(unsafe.Pointer(&buf[0])) // want "cast of a byte buffer element to \\*uint32 may be misaligned: the address is only known to be 1-byte aligned"
+}
@@ -0,0 +1,55 @@
+package aligned_cast
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+func FreshBuffer() uint64 {
+	buf := make([]byte, 64)
+	return *(*uint64)(unsafe.Pointer(&buf[8]))
+}
+
+func ScaledIndex(i int) uint64 {
+	buf := new([64]byte)
+	return *(*uint64)(unsafe.Pointer(&buf[i*8]))
+}
+
+func ShiftedIndex(i int) uint32 {
+	buf := make([]byte, 64)
+	return *(*uint32)(unsafe.Pointer(&buf[i<<2+4]))
+}
+
+func ByteSized(buf []byte) *[4]byte {
+	return (*[4]byte)(unsafe.Pointer(&buf[1]))
+}
+
+func UnsafeAdd(p *uint64, i int) *uint32 {
+	return (*uint32)(unsafe.Add(unsafe.Pointer(p), 4*i))
+}
+
+func AtomicCounter() uint64 {
+	buf := make([]byte, 64)
+	return atomic.LoadUint64((*uint64)(unsafe.Pointer(&buf[16])))
+}
+
+func Load[T any](b []byte) *T {
+	// the alignment of T is only known once it is instantiated
+	return (*T)(unsafe.Pointer(&b[0]))
+}
+
+func ShortAlignedBuffer() uint64 {
+	buf := make([]byte, 8)
+	return *(*uint64)(unsafe.Pointer(&buf[0]))
+}
+
+func ShortWordBuffer() uint32 {
+	// the tiny allocator aligns buffers whose size is a multiple of 4 to 4 bytes
+	buf := make([]byte, 12)
+	return *(*uint32)(unsafe.Pointer(&buf[4]))
+}
+
+func LargeCapacity(n int) uint64 {
+	buf := make([]byte, n, 24)
+	return *(*uint64)(unsafe.Pointer(&buf[0]))
+}
@@ -0,0 +1,199 @@
+package atomicalign
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/jlauinger/go-safer/passes/internal/layout"
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+)
+
+// Analyzer is a golang.org/x/tools/go/analysis style linter pass.
+// Use this with the Vet-style infrastructure.
+var Analyzer = &analysis.Analyzer{
+	Name:             "atomicalign",
+	Doc:              "reports 64-bit atomic operations on struct fields that are not 8-byte aligned on 32-bit architectures",
+	Run:              run,
+	Requires:         []*analysis.Analyzer{inspect.Analyzer},
+	RunDespiteErrors: true,
+}
+
+// architectures are the 32-bit GOARCH values where 64-bit words are only 4-byte aligned, but 64-bit atomic operations
+// need 8-byte alignment
+var architectures = []string{"386", "arm", "mips", "mipsle"}
+
+// position describes where a value lies relative to the start of its allocation: at offset plus any multiple of
+// stride. A stride of zero means that the offset is exact
+type position struct {
+	offset int64
+	stride int64
+}
+
+/**
+ * run is the entry point to the analysis pass
+ */
+func run(pass *analysis.Pass) (interface{}, error) {
+	// get results from required inspect analyzer
+	inspectResult := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	inspectResult.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		node := n.(*ast.CallExpr)
+
+		// find calls to the 64-bit functions of sync/atomic, which take a pointer as the first argument
+		callee, ok := typeutil.Callee(pass.TypesInfo, node).(*types.Func)
+		if !ok || callee.Pkg() == nil || callee.Pkg().Path() != "sync/atomic" || len(node.Args) == 0 {
+			return
+		}
+		name := callee.Name()
+		if !strings.HasSuffix(name, "Int64") && !strings.HasSuffix(name, "Uint64") {
+			return
+		}
+
+		// the pointer must be the address of a field or an element, otherwise it is the first word of an allocation or
+		// a variable, which is always aligned
+		address, ok := ast.Unparen(node.Args[0]).(*ast.UnaryExpr)
+		if !ok || address.Op != token.AND {
+			return
+		}
+		switch ast.Unparen(address.X).(type) {
+		case *ast.SelectorExpr, *ast.IndexExpr:
+		default:
+			return
+		}
+
+		// compute where the field lies within its allocation on each 32-bit architecture
+		var misaligned []string
+		var first position
+		for _, arch := range architectures {
+			p, ok := positionOf(address.X, types.SizesFor("gc", arch), pass)
+			if !ok {
+				return
+			}
+			if p.offset%8 != 0 || p.stride%8 != 0 {
+				if misaligned == nil {
+					first = p
+				}
+				misaligned = append(misaligned, arch)
+			}
+		}
+		if len(misaligned) == 0 {
+			return
+		}
+
+		// elements of arrays and slices lie at a multiple of the element size, which can misalign them as well
+		where := fmt.Sprintf("offset %d in its allocation", first.offset)
+		if first.stride != 0 {
+			where = fmt.Sprintf("offset %d plus a multiple of the element size %d in its allocation", first.offset,
+				first.stride)
+		}
+		replacement := "atomic.Int64"
+		if strings.HasSuffix(name, "Uint64") {
+			replacement = "atomic.Uint64"
+		}
+		pass.Reportf(address.Pos(), "64-bit atomic operation on %s, which is not 8-byte aligned on %s (%s); use %s "+
+			"for the field, or move it to the start of the struct", types.ExprString(address.X),
+			strings.Join(misaligned, ", "), where, replacement)
+	})
+
+	return nil, nil
+}
+
+/**
+ * computes where the value that an expression refers to lies within its allocation. Variables, dereferenced pointers
+ * and slice elements start at an allocation, which is 8-byte aligned. The second return value is false if the
+ * position is unknown
+ */
+func positionOf(expr ast.Expr, sizes types.Sizes, pass *analysis.Pass) (position, bool) {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		// variables are aligned like allocations
+		_, ok := pass.TypesInfo.ObjectOf(e).(*types.Var)
+		return position{}, ok
+	case *ast.StarExpr:
+		return position{}, true
+	case *ast.SelectorExpr:
+		selection, ok := pass.TypesInfo.Selections[e]
+		if !ok || selection.Kind() != types.FieldVal {
+			return position{}, false
+		}
+		// selecting through a pointer starts at a new allocation
+		var base position
+		t := selection.Recv()
+		if _, ok := t.Underlying().(*types.Pointer); !ok {
+			base, ok = positionOf(e.X, sizes, pass)
+			if !ok {
+				return position{}, false
+			}
+		}
+		return fieldPosition(base, t, selection.Index(), sizes)
+	case *ast.IndexExpr:
+		t := pass.TypesInfo.TypeOf(e.X)
+		if t == nil || resolve.ContainsTypeParam(t) {
+			return position{}, false
+		}
+		switch container := t.Underlying().(type) {
+		case *types.Array:
+			// elements of an array value lie within the allocation of the array
+			base, ok := positionOf(e.X, sizes, pass)
+			if !ok {
+				return position{}, false
+			}
+			return elementPosition(base, e.Index, sizes.Sizeof(container.Elem()), pass), true
+		case *types.Slice:
+			return elementPosition(position{}, e.Index, sizes.Sizeof(container.Elem()), pass), true
+		case *types.Pointer:
+			if array, ok := container.Elem().Underlying().(*types.Array); ok {
+				return elementPosition(position{}, e.Index, sizes.Sizeof(array.Elem()), pass), true
+			}
+		}
+	}
+	return position{}, false
+}
+
+/**
+ * moves a position to an element of the array or slice that starts there. A constant index moves it by exactly that
+ * many elements, any other index by any multiple of the element size
+ */
+func elementPosition(base position, index ast.Expr, elemSize int64, pass *analysis.Pass) position {
+	if value := pass.TypesInfo.Types[index].Value; value != nil {
+		if i, exact := constant.Int64Val(constant.ToInt(value)); exact {
+			return position{base.offset + i*elemSize, base.stride}
+		}
+	}
+	return position{base.offset, layout.GCD(base.stride, elemSize)}
+}
+
+/**
+ * moves a position along the path of field indices of a selection, which includes the embedded fields that a promoted
+ * field is selected through
+ */
+func fieldPosition(base position, t types.Type, path []int, sizes types.Sizes) (position, bool) {
+	for _, index := range path {
+		if pointer, ok := t.Underlying().(*types.Pointer); ok {
+			// embedded pointers point to a new allocation
+			base = position{}
+			t = pointer.Elem()
+		}
+		// the offsets of fields that follow a type parameter are only known once it is instantiated
+		s, ok := t.Underlying().(*types.Struct)
+		if !ok || resolve.ContainsTypeParam(s) {
+			return position{}, false
+		}
+		fields := make([]*types.Var, s.NumFields())
+		for i := range fields {
+			fields[i] = s.Field(i)
+		}
+		base.offset += sizes.Offsetsof(fields)[index]
+		t = s.Field(index).Type()
+	}
+	return base, true
+}
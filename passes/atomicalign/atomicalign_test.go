@@ -0,0 +1,18 @@
+package atomicalign_test
+
+import (
+	"github.com/jlauinger/go-safer/passes/atomicalign"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"testing"
+)
+
+func Test(t *testing.T) {
+	// use go vet infrastructure testing and supply annotated code examples
+	testdata := analysistest.TestData()
+	testPackages := []string{
+		"bad/unaligned_counter",
+
+		"good/aligned_counter",
+	}
+	analysistest.Run(t, testdata, atomicalign.Analyzer, testPackages...)
+}
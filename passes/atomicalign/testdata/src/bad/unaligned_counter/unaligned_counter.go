@@ -0,0 +1,69 @@
+package unaligned_counter
+
+import "sync/atomic"
+
+type stats struct {
+	ready bool
+	hits  int64
+}
+
+type server struct {
+	name  string
+	stats stats
+	total uint64
+}
+
+type wrapper struct {
+	id int64
+	stats
+}
+
+var global stats
+
+func Count(s *stats) {
+	atomic.AddInt64(&s.hits, 1) // want "64-bit atomic operation on s.hits, which is not 8-byte aligned on 386, arm, mips, mipsle \\(offset 4 in its allocation\\); use atomic.Int64 for the field, or move it to the start of the struct"
+}
+
+func Load() int64 {
+	return atomic.LoadInt64(&global.hits) // want "64-bit atomic operation on global.hits, which is not 8-byte aligned on 386, arm, mips, mipsle \\(offset 4 in its allocation\\)"
+}
+
+func Nested(srv *server) uint64 {
+	// name takes 8 bytes, so stats starts aligned, but hits lies 4 bytes into it
+	atomic.StoreInt64(&srv.stats.hits, 0) // want "64-bit atomic operation on srv.stats.hits, which is not 8-byte aligned on 386, arm, mips, mipsle \\(offset 12 in its allocation\\)"
+	// stats takes 12 bytes, so total lies at offset 20
+	return atomic.AddUint64(&srv.total, 1) // want "64-bit atomic operation on srv.total, which is not 8-byte aligned on 386, arm, mips, mipsle \\(offset 20 in its allocation\\); use atomic.Uint64 for the field"
+}
+
+func Promoted(w *wrapper) {
+	atomic.AddInt64(&w.hits, 1) // want "64-bit atomic operation on w.hits, which is not 8-byte aligned on 386, arm, mips, mipsle \\(offset 12 in its allocation\\)"
+}
+
+func Elements(all []server, i int) {
+	// total lies at offset 20 of the first element
+	atomic.CompareAndSwapUint64(&all[0].total, 0, 1) // want "64-bit atomic operation on all\\[0\\].total, which is not 8-byte aligned on 386, arm, mips, mipsle \\(offset 20 in its allocation\\)"
+	// the elements take 28 bytes, so they are not aligned alike
+	atomic.CompareAndSwapUint64(&all[i].total, 0, 1) // want "64-bit atomic operation on all\\[i\\].total, which is not 8-byte aligned on 386, arm, mips, mipsle \\(offset 20 plus a multiple of the element size 28 in its allocation\\)"
+}
+
+type entry struct {
+	n     int64
+	ready bool
+}
+
+func Stride(entries []entry, i int) {
+	// n is the first field, but the elements take 12 bytes, so every other one is misaligned
+	atomic.AddInt64(&entries[1].n, 1) // want "64-bit atomic operation on entries\\[1\\].n, which is not 8-byte aligned on 386, arm, mips, mipsle \\(offset 12 in its allocation\\)"
+	atomic.AddInt64(&entries[i].n, 1) // want "64-bit atomic operation on entries\\[i\\].n, which is not 8-byte aligned on 386, arm, mips, mipsle \\(offset 0 plus a multiple of the element size 12 in its allocation\\)"
+}
+
+type histogram struct {
+	ready   bool
+	buckets [4]int64
+}
+
+func ArrayField(h *histogram, i int) {
+	// the array starts 4 bytes into the struct, and its elements keep that offset
+	atomic.AddInt64(&h.buckets[1], 1) // want "64-bit atomic operation on h.buckets\\[1\\], which is not 8-byte aligned on 386, arm, mips, mipsle \\(offset 12 in its allocation\\)"
+	atomic.AddInt64(&h.buckets[i], 1) // want "64-bit atomic operation on h.buckets\\[i\\], which is not 8-byte aligned on 386, arm, mips, mipsle \\(offset 4 plus a multiple of the element size 8 in its allocation\\)"
+}
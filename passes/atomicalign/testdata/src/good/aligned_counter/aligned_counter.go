@@ -0,0 +1,101 @@
+package aligned_counter
+
+import "sync/atomic"
+
+type stats struct {
+	hits  int64
+	ready bool
+}
+
+type server struct {
+	total uint64
+	stats stats
+	name  string
+}
+
+type modern struct {
+	ready bool
+	hits  atomic.Int64
+}
+
+type pair struct {
+	a, b int64
+}
+
+var counter int64
+
+func Count(s *stats) {
+	// the first word of an allocated struct is aligned
+	atomic.AddInt64(&s.hits, 1)
+}
+
+func Global() int64 {
+	// global variables are aligned
+	return atomic.LoadInt64(&counter)
+}
+
+func Nested(srv *server) uint64 {
+	// total takes 8 bytes, so stats starts aligned
+	atomic.StoreInt64(&srv.stats.hits, 0)
+	return atomic.AddUint64(&srv.total, 1)
+}
+
+func Modern(m *modern) {
+	// the atomic types align themselves
+	m.hits.Add(1)
+}
+
+func Elements(pairs []pair, counters []int64) {
+	// elements of 16 bytes keep the alignment of the slice
+	atomic.AddInt64(&pairs[3].b, 1)
+	atomic.AddInt64(&counters[1], 1)
+}
+
+type unevenServer struct {
+	name  string
+	stats struct {
+		ready bool
+		hits  int64
+	}
+	total uint64
+}
+
+func ConstantElement(all []unevenServer) uint64 {
+	// the elements take 28 bytes, so total of the second element lies at the aligned offset 48
+	return atomic.AddUint64(&all[1].total, 1)
+}
+
+func Unknown(get func() *int64) {
+	// the address is not a field, so nothing is known about it
+	atomic.AddInt64(get(), 1)
+}
+
+func Small(s *struct {
+	ready bool
+	flags int32
+}) {
+	// 32-bit operations only need 4-byte alignment
+	atomic.AddInt32(&s.flags, 1)
+}
+
+type Box[T any] struct {
+	v T
+	n int64
+}
+
+func Generic[T any](b *Box[T], boxes []Box[T]) {
+	// the offset of n depends on the size of T, which is only known once it is instantiated
+	atomic.AddInt64(&b.n, 1)
+	atomic.AddInt64(&boxes[1].n, 1)
+}
+
+type histogram struct {
+	buckets [4]int64
+	ready   bool
+}
+
+func ArrayField(h *histogram, local [2]uint64) {
+	// arrays of 64-bit words that start aligned keep every element aligned
+	atomic.AddInt64(&h.buckets[1], 1)
+	atomic.AddUint64(&local[1], 1)
+}
@@ -0,0 +1,190 @@
+package endianness
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/jlauinger/go-safer/passes/internal/layout"
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+)
+
+// Analyzer is a golang.org/x/tools/go/analysis style linter pass.
+// Use this with the Vet-style infrastructure.
+var Analyzer = &analysis.Analyzer{
+	Name:             "endianness",
+	Doc:              "reports reinterpretations of byte buffers as multi-byte values, which depend on the host byte order",
+	Run:              run,
+	Requires:         []*analysis.Analyzer{inspect.Analyzer},
+	RunDespiteErrors: true,
+}
+
+// bigEndianArchitectures are the GOARCH values where multi-byte values are stored with the most significant byte first
+var bigEndianArchitectures = []string{"mips", "mips64", "ppc64", "s390x"}
+
+/**
+ * run is the entry point to the analysis pass
+ */
+func run(pass *analysis.Pass) (interface{}, error) {
+	// get results from required inspect analyzer
+	inspectResult := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	architectures := strings.Join(bigEndianArchitectures, ", ")
+
+	inspectResult.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		node := n.(*ast.CallExpr)
+
+		// find casts of the form (*T)(unsafe.Pointer(...))
+		target, ok := pass.TypesInfo.Types[node.Fun]
+		if !ok || !target.IsType() || len(node.Args) != 1 {
+			return true
+		}
+		pointer, ok := target.Type.Underlying().(*types.Pointer)
+		if !ok || !resolve.IsUnsafePointerType(pass.TypesInfo.TypeOf(node.Args[0])) {
+			return true
+		}
+
+		// the source must be a byte buffer
+		if !isByteBuffer(node.Args[0], pointer.Elem(), pass) {
+			return true
+		}
+
+		// the target must contain values of more than one byte
+		qualifier := types.RelativeTo(pass.Pkg)
+		fields := multiByteFields(pointer.Elem(), "", qualifier)
+		if len(fields) == 0 {
+			return true
+		}
+		targetName := types.TypeString(pointer.Elem(), qualifier)
+
+		// scalar values have a single field without a name
+		if len(fields) == 1 && fields[0] == targetName {
+			pass.Report(analysis.Diagnostic{
+				Pos: node.Pos(),
+				End: node.End(),
+				Message: "reinterpreting a byte buffer as " + targetName + " uses the host byte order, so the " +
+					"result differs on the big-endian architectures " + architectures,
+				SuggestedFixes: byteOrderFixes(node, pointer.Elem(), stack, pass),
+			})
+			return true
+		}
+
+		pass.Reportf(node.Pos(), "reinterpreting a byte buffer as %s uses the host byte order for %s, so the result "+
+			"differs on the big-endian architectures %s", targetName, strings.Join(fields, ", "), architectures)
+		return true
+	})
+
+	return nil, nil
+}
+
+/**
+ * checks whether an unsafe.Pointer expression points into a byte buffer: the address of a byte slice or array
+ * element, the address of a byte array, the data of a byte slice, or the address of a byte slice that is cast to a
+ * different slice type
+ */
+func isByteBuffer(expr ast.Expr, target types.Type, pass *analysis.Pass) bool {
+	expr = ast.Unparen(expr)
+	switch e := expr.(type) {
+	case *ast.CallExpr:
+		if resolve.IsUnsafePointerConversion(pass.TypesInfo, e) {
+			return isByteBuffer(e.Args[0], target, pass)
+		}
+		if resolve.IsPackageObject(pass.TypesInfo, e.Fun, "unsafe", "SliceData") && len(e.Args) == 1 {
+			return layout.IsBytes(pass.TypesInfo.TypeOf(e.Args[0]))
+		}
+		if resolve.IsPackageObject(pass.TypesInfo, e.Fun, "unsafe", "Add") && len(e.Args) == 2 {
+			return isByteBuffer(e.Args[0], target, pass)
+		}
+	case *ast.UnaryExpr:
+		if e.Op != token.AND {
+			return false
+		}
+		if _, _, ok := layout.ByteElementAddress(pass.TypesInfo, e); ok {
+			return true
+		}
+		// the address of a byte slice is only a buffer if it is reinterpreted as another slice
+		t := pass.TypesInfo.TypeOf(e.X)
+		if t == nil {
+			return false
+		}
+		if _, ok := t.Underlying().(*types.Slice); ok {
+			_, targetIsSlice := target.Underlying().(*types.Slice)
+			return targetIsSlice && layout.IsBytes(t)
+		}
+		return layout.IsBytes(t)
+	}
+
+	// pointers to byte arrays
+	t := pass.TypesInfo.TypeOf(expr)
+	if t == nil {
+		return false
+	}
+	if pointer, ok := t.Underlying().(*types.Pointer); ok {
+		if _, ok := pointer.Elem().Underlying().(*types.Array); ok {
+			return layout.IsBytes(pointer.Elem())
+		}
+	}
+	return false
+}
+
+/**
+ * lists the parts of a type that are numbers of more than one byte. Scalar types are described by their type name,
+ * and struct fields and array elements by their path
+ */
+func multiByteFields(t types.Type, path string, qualifier types.Qualifier) []string {
+	describe := func() string {
+		if path == "" {
+			return types.TypeString(t, qualifier)
+		}
+		return path
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		if u.Info()&types.IsNumeric != 0 && !isSingleByte(u) {
+			return []string{describe()}
+		}
+	case *types.Array:
+		// all elements have the same type, so the first one describes the whole array
+		if u.Len() == 0 {
+			return nil
+		}
+		if len(multiByteFields(u.Elem(), "", qualifier)) > 0 {
+			return []string{describe()}
+		}
+	case *types.Slice:
+		// slices are only reinterpreted by casting their header, and then every element is
+		return multiByteFields(u.Elem(), path, qualifier)
+	case *types.Struct:
+		var fields []string
+		for i := 0; i < u.NumFields(); i++ {
+			field := u.Field(i)
+			name := field.Name()
+			if path != "" {
+				name = path + "." + name
+			}
+			fields = append(fields, multiByteFields(field.Type(), name, qualifier)...)
+		}
+		return fields
+	}
+	return nil
+}
+
+/**
+ * checks whether a basic type is a number of a single byte
+ */
+func isSingleByte(basic *types.Basic) bool {
+	switch basic.Kind() {
+	case types.Int8, types.Uint8:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package endianness_test
+
+import (
+	"github.com/jlauinger/go-safer/passes/endianness"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"testing"
+)
+
+func Test(t *testing.T) {
+	// use go vet infrastructure testing and supply annotated code examples
+	testdata := analysistest.TestData()
+	testPackages := []string{
+		"bad/scalar_read",
+		"bad/single_read",
+		"bad/struct_decode",
+
+		"good/byte_order",
+	}
+	analysistest.RunWithSuggestedFixes(t, testdata, endianness.Analyzer, testPackages...)
+}
@@ -0,0 +1,152 @@
+package endianness
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/jlauinger/go-safer/passes/internal/astedit"
+	"github.com/jlauinger/go-safer/passes/internal/layout"
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+)
+
+/**
+ * builds the suggested fixes for reading a scalar integer from a byte buffer, like *(*uint32)(unsafe.Pointer(&b[i])).
+ * There is one fix for each byte order, because only the author knows which one the data is encoded in
+ */
+func byteOrderFixes(cast *ast.CallExpr, target types.Type, stack []ast.Node,
+	pass *analysis.Pass) []analysis.SuggestedFix {
+	// only reads of the value can be replaced, so the cast must be dereferenced and not be written to
+	read, ok := dereferencedRead(cast, stack)
+	if !ok {
+		return nil
+	}
+
+	// only integers with a fixed size have a matching function in encoding/binary
+	basic, ok := target.Underlying().(*types.Basic)
+	if !ok {
+		return nil
+	}
+	var bits int
+	switch basic.Kind() {
+	case types.Int16, types.Uint16:
+		bits = 16
+	case types.Int32, types.Uint32:
+		bits = 32
+	case types.Int64, types.Uint64:
+		bits = 64
+	default:
+		return nil
+	}
+
+	// the buffer is sliced at the offset of the element
+	buffer, ok := bufferSlice(cast.Args[0], pass)
+	if !ok {
+		return nil
+	}
+
+	// the binary package must be usable in the file
+	file := astedit.EnclosingFile(cast, pass)
+	if file == nil {
+		return nil
+	}
+	var importEdits []analysis.TextEdit
+	binaryName, imported := astedit.ImportName(file, "encoding/binary")
+	if !imported {
+		binaryName = "binary"
+		if _, object := pass.Pkg.Scope().Innermost(cast.Pos()).LookupParent(binaryName, cast.Pos()); object != nil {
+			return nil
+		}
+		importEdits = append(importEdits, astedit.AddImport(file, "encoding/binary", pass))
+	}
+
+	// named and signed types are converted from the unsigned result
+	typeName, ok := astedit.TypeString(target, file, pass)
+	if !ok {
+		return nil
+	}
+	unsigned := types.Typ[map[int]types.BasicKind{16: types.Uint16, 32: types.Uint32, 64: types.Uint64}[bits]]
+
+	var fixes []analysis.SuggestedFix
+	for _, order := range []string{"LittleEndian", "BigEndian"} {
+		replacement := fmt.Sprintf("%s.%s.Uint%d(%s)", binaryName, order, bits, buffer)
+		if !types.Identical(target, unsigned) {
+			replacement = fmt.Sprintf("%s(%s)", typeName, replacement)
+		}
+		edits := append([]analysis.TextEdit{{Pos: read.Pos(), End: read.End(), NewText: []byte(replacement)}},
+			importEdits...)
+		// the unsafe import might have become unused now
+		edits = append(edits, astedit.RemoveUnusedImport(file, "unsafe", []ast.Node{read}, !imported, pass)...)
+		fixes = append(fixes, analysis.SuggestedFix{
+			Message:   "read with binary." + order,
+			TextEdits: edits,
+		})
+	}
+	return fixes
+}
+
+/**
+ * checks whether a cast is dereferenced to read the value, and returns the dereference expression
+ */
+func dereferencedRead(cast *ast.CallExpr, stack []ast.Node) (*ast.StarExpr, bool) {
+	if len(stack) < 3 {
+		return nil, false
+	}
+	star, ok := stack[len(stack)-2].(*ast.StarExpr)
+	if !ok {
+		return nil, false
+	}
+	switch parent := stack[len(stack)-3].(type) {
+	case *ast.AssignStmt:
+		for _, lhs := range parent.Lhs {
+			if lhs == star {
+				return nil, false
+			}
+		}
+	case *ast.IncDecStmt:
+		return nil, false
+	case *ast.UnaryExpr:
+		if parent.Op == token.AND {
+			return nil, false
+		}
+	}
+	return star, true
+}
+
+/**
+ * returns the source code of the byte slice that starts at the address an unsafe.Pointer expression points to, which
+ * must be a byte element address like &b[i] or the data of a byte slice
+ */
+func bufferSlice(expr ast.Expr, pass *analysis.Pass) (string, bool) {
+	expr = ast.Unparen(expr)
+	if call, ok := expr.(*ast.CallExpr); ok {
+		if resolve.IsUnsafePointerConversion(pass.TypesInfo, call) {
+			return bufferSlice(call.Args[0], pass)
+		}
+		if resolve.IsPackageObject(pass.TypesInfo, call.Fun, "unsafe", "SliceData") && len(call.Args) == 1 {
+			if t := pass.TypesInfo.TypeOf(call.Args[0]); t != nil && layout.IsBytes(t) {
+				return astedit.Render(call.Args[0], pass), true
+			}
+		}
+		return "", false
+	}
+
+	buffer, index, ok := layout.ByteElementAddress(pass.TypesInfo, expr)
+	if !ok {
+		return "", false
+	}
+	bufferText := astedit.Render(buffer, pass)
+
+	// slices at offset zero can be used directly
+	if value := pass.TypesInfo.Types[index].Value; value != nil && constant.Sign(value) == 0 {
+		if _, ok := pass.TypesInfo.TypeOf(buffer).Underlying().(*types.Slice); ok {
+			return bufferText, true
+		}
+		return bufferText + "[:]", true
+	}
+	return fmt.Sprintf("%s[%s:]", bufferText, astedit.Render(index, pass)), true
+}
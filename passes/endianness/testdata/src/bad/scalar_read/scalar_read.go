@@ -0,0 +1,32 @@
+package scalar_read
+
+import (
+	"encoding/binary"
+	"unsafe"
+)
+
+type Port uint16
+
+func Length(packet []byte) uint32 {
+	return *(*uint32)(unsafe.Pointer(&packet[4])) // want "reinterpreting a byte buffer as uint32 uses the host byte order, so the result differs on the big-endian architectures mips, mips64, ppc64, s390x"
+}
+
+func Sequence(packet []byte) int64 {
+	return *(*int64)(unsafe.Pointer(&packet[0])) // want "reinterpreting a byte buffer as int64 uses the host byte order, so the result differs on the big-endian architectures mips, mips64, ppc64, s390x"
+}
+
+func DestinationPort(header *[20]byte) Port {
+	return *(*Port)(unsafe.Pointer(&header[2])) // want "reinterpreting a byte buffer as Port uses the host byte order, so the result differs on the big-endian architectures mips, mips64, ppc64, s390x"
+}
+
+func Checksum(packet []byte) uint16 {
+	return *(*uint16)(unsafe.Pointer(unsafe.SliceData(packet))) // want "reinterpreting a byte buffer as uint16 uses the host byte order, so the result differs on the big-endian architectures mips, mips64, ppc64, s390x"
+}
+
+func SetLength(packet []byte, length uint32) {
+	*(*uint32)(unsafe.Pointer(&packet[4])) = length // want "reinterpreting a byte buffer as uint32 uses the host byte order, so the result differs on the big-endian architectures mips, mips64, ppc64, s390x"
+}
+
+func Flags(packet []byte) uint16 {
+	return binary.BigEndian.Uint16(packet[2:])
+}
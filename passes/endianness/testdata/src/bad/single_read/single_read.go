@@ -0,0 +1,10 @@
+package single_read
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+func PrintMagic(file []byte) {
+	fmt.Println(*(*uint32)(unsafe.Pointer(&file[0]))) // want "reinterpreting a byte buffer as uint32 uses the host byte order, so the result differs on the big-endian architectures mips, mips64, ppc64, s390x"
+}
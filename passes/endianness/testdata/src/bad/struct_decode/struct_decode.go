@@ -0,0 +1,38 @@
+package struct_decode
+
+import (
+	"unsafe"
+)
+
+type ipHeader struct {
+	VersionIHL  uint8
+	TOS         uint8
+	TotalLength uint16
+	ID          uint16
+	Fragment    uint16
+	TTL         uint8
+	Protocol    uint8
+	Checksum    uint16
+	Addresses   [2][4]byte
+}
+
+type record struct {
+	Key    [4]byte
+	Values [2]uint32
+}
+
+func DecodeHeader(packet []byte) *ipHeader {
+	return (*ipHeader)(unsafe.Pointer(&packet[0])) // want "reinterpreting a byte buffer as ipHeader uses the host byte order for TotalLength, ID, Fragment, Checksum, so the result differs on the big-endian architectures mips, mips64, ppc64, s390x"
+}
+
+func DecodeRecord(buf *[12]byte) record {
+	return *(*record)(unsafe.Pointer(buf)) // want "reinterpreting a byte buffer as record uses the host byte order for Values, so the result differs on the big-endian architectures mips, mips64, ppc64, s390x"
+}
+
+func Words(buf []byte) []uint32 {
+	return *(*[]uint32)(unsafe.Pointer(&buf)) // want "reinterpreting a byte buffer as \\[\\]uint32 uses the host byte order for uint32, so the result differs on the big-endian architectures mips, mips64, ppc64, s390x"
+}
+
+func WordPointer(buf []byte, i int) *uint64 {
+	return (*uint64)(unsafe.Add(unsafe.Pointer(&buf[0]), 8*i)) // want "reinterpreting a byte buffer as uint64 uses the host byte order, so the result differs on the big-endian architectures mips, mips64, ppc64, s390x"
+}
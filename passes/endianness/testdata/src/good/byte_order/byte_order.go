@@ -0,0 +1,27 @@
+package byte_order
+
+import (
+	"encoding/binary"
+	"unsafe"
+)
+
+type flags struct {
+	A uint8
+	B int8
+}
+
+func Length(packet []byte) uint32 {
+	return binary.BigEndian.Uint32(packet[4:])
+}
+
+func Flags(packet []byte) flags {
+	return *(*flags)(unsafe.Pointer(&packet[0]))
+}
+
+func Prefix(packet []byte) *[4]byte {
+	return (*[4]byte)(unsafe.Pointer(&packet[0]))
+}
+
+func Value(p *uint64) uint32 {
+	return *(*uint32)(unsafe.Pointer(p))
+}
@@ -0,0 +1,571 @@
+package headermigration
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"go/version"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/jlauinger/go-safer/passes/internal/astedit"
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+)
+
+// minimum Go versions that provide the unsafe functions used by the fixes
+const (
+	unsafeSliceGoVersion  = "go1.17"
+	unsafeStringGoVersion = "go1.20"
+)
+
+// headerVariable is a variable that holds a pointer to the header of a real slice or string, like
+// h := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+type headerVariable struct {
+	object *types.Var
+	// stmt is the statement that defines the variable, and block the block that contains it
+	stmt  *ast.AssignStmt
+	block *ast.BlockStmt
+	// typeIdent is the identifier of the header type in the cast
+	typeIdent *ast.Ident
+	// source is the slice or string variable that the header is cast from
+	source *ast.Ident
+	slice  bool
+	// reads are the field selections of the variable that read a field, and writes the statements that assign to one.
+	// other is true if the variable is used in any other way
+	reads  []*ast.SelectorExpr
+	writes []*ast.AssignStmt
+	other  bool
+}
+
+// rewrite collects the edits of a fix together with the Go version they need
+type rewrite struct {
+	pass       *analysis.Pass
+	file       *ast.File
+	unsafeName string
+	edits      []analysis.TextEdit
+	removed    []ast.Node
+	minVersion string
+	usesUnsafe bool
+}
+
+/**
+ * finds the header variables in a package that can be replaced with the unsafe functions, and builds the fixes for
+ * them. The fixes are returned by the identifier of the header type in the definition of the variable
+ */
+func migrationFixes(inspectResult *inspector.Inspector, pass *analysis.Pass) map[*ast.Ident][]analysis.SuggestedFix {
+	// collect the header variables and how they are used
+	var headers []*headerVariable
+	inspectResult.WithStack([]ast.Node{(*ast.AssignStmt)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		if header := headerDefinition(n.(*ast.AssignStmt), stack, pass); header != nil {
+			headers = append(headers, header)
+		}
+		return true
+	})
+
+	// headers that are only read from and whose reads are all part of the assignments to another header are replaced
+	// together with that header, so that the edits do not overlap
+	absorbed := make(map[*headerVariable]*headerVariable)
+	for _, written := range headers {
+		if !written.replaceable() || len(written.writes) == 0 {
+			continue
+		}
+		for _, read := range headers {
+			if read != written && read.replaceable() && len(read.writes) == 0 && read.block == written.block &&
+				readsWithin(read, written.writes) {
+				absorbed[read] = written
+			}
+		}
+	}
+
+	fixes := make(map[*ast.Ident][]analysis.SuggestedFix)
+	for _, header := range headers {
+		if !header.replaceable() || absorbed[header] != nil {
+			continue
+		}
+		var reads []*headerVariable
+		for read, written := range absorbed {
+			if written == header {
+				reads = append(reads, read)
+			}
+		}
+		if fix, ok := headerFix(header, reads, pass); ok {
+			fixes[header.typeIdent] = []analysis.SuggestedFix{fix}
+		}
+	}
+	return fixes
+}
+
+/**
+ * checks whether an assignment defines a header variable by casting the address of a slice or string variable, and
+ * collects the uses of the variable if so
+ */
+func headerDefinition(stmt *ast.AssignStmt, stack []ast.Node, pass *analysis.Pass) *headerVariable {
+	// the statement must be a single definition directly in a block
+	if stmt.Tok != token.DEFINE || len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 || len(stack) < 2 {
+		return nil
+	}
+	block, ok := stack[len(stack)-2].(*ast.BlockStmt)
+	if !ok {
+		return nil
+	}
+	lhs, ok := stmt.Lhs[0].(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	object, ok := pass.TypesInfo.Defs[lhs].(*types.Var)
+	if !ok {
+		return nil
+	}
+
+	// the value must be a cast of the form (*T)(unsafe.Pointer(&source)), where T is a header type
+	cast, ok := ast.Unparen(stmt.Rhs[0]).(*ast.CallExpr)
+	if !ok || len(cast.Args) != 1 {
+		return nil
+	}
+	typeIdent := headerTypeIdent(cast.Fun, pass)
+	if typeIdent == nil {
+		return nil
+	}
+	pointerCall, ok := ast.Unparen(cast.Args[0]).(*ast.CallExpr)
+	if !ok || !resolve.IsUnsafePointerConversion(pass.TypesInfo, pointerCall) {
+		return nil
+	}
+	address, ok := ast.Unparen(pointerCall.Args[0]).(*ast.UnaryExpr)
+	if !ok || address.Op != token.AND {
+		return nil
+	}
+	source, ok := ast.Unparen(address.X).(*ast.Ident)
+	if !ok || pass.TypesInfo.Uses[source] == nil {
+		return nil
+	}
+
+	// the source must match the kind of header
+	slice := resolve.ReflectHeaderName(pass.TypesInfo.TypeOf(cast)) == "SliceHeader"
+	switch t := pass.TypesInfo.TypeOf(source).Underlying().(type) {
+	case *types.Slice:
+		if !slice {
+			return nil
+		}
+	case *types.Basic:
+		if slice || t.Info()&types.IsString == 0 {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	header := &headerVariable{
+		object:    object,
+		stmt:      stmt,
+		block:     block,
+		typeIdent: typeIdent,
+		source:    source,
+		slice:     slice,
+	}
+	header.collectUses(astedit.EnclosingFuncBody(stack), pass)
+	return header
+}
+
+/**
+ * returns the identifier of a header type in a type expression of the form (*T), or nil if it is not one
+ */
+func headerTypeIdent(expr ast.Expr, pass *analysis.Pass) *ast.Ident {
+	star, ok := ast.Unparen(expr).(*ast.StarExpr)
+	if !ok {
+		return nil
+	}
+	var ident *ast.Ident
+	switch t := ast.Unparen(star.X).(type) {
+	case *ast.Ident:
+		ident = t
+	case *ast.SelectorExpr:
+		ident = t.Sel
+	default:
+		return nil
+	}
+	typeName, ok := pass.TypesInfo.Uses[ident].(*types.TypeName)
+	if !ok || !resolve.IsReflectHeader(typeName.Type()) {
+		return nil
+	}
+	return ident
+}
+
+/**
+ * sorts the uses of a header variable within a function body into field reads, field assignments, and other uses
+ */
+func (h *headerVariable) collectUses(body *ast.BlockStmt, pass *analysis.Pass) {
+	if body == nil {
+		h.other = true
+		return
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			// a single assignment to a field, with a value that does not use the header itself
+			if node.Tok == token.ASSIGN && len(node.Lhs) == 1 && len(node.Rhs) == 1 && h.isField(node.Lhs[0], pass) {
+				h.writes = append(h.writes, node)
+				ast.Inspect(node.Rhs[0], h.inspectUse(pass))
+				return false
+			}
+		case *ast.UnaryExpr:
+			// taking the address of a field could be used to write to it
+			if node.Op == token.AND && h.isField(node.X, pass) {
+				h.other = true
+				return false
+			}
+		}
+		return h.inspectUse(pass)(n)
+	})
+}
+
+/**
+ * returns a function for ast.Inspect that records field reads and other uses of the header variable
+ */
+func (h *headerVariable) inspectUse(pass *analysis.Pass) func(ast.Node) bool {
+	return func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.IncDecStmt:
+			if h.isField(node.X, pass) {
+				h.other = true
+				return false
+			}
+		case *ast.AssignStmt:
+			// all remaining assignments to fields, like compound or multiple assignments, are not rewritten
+			for _, lhs := range node.Lhs {
+				if h.isField(lhs, pass) {
+					h.other = true
+				}
+			}
+		case *ast.SelectorExpr:
+			if h.isField(node, pass) {
+				h.reads = append(h.reads, node)
+				return false
+			}
+		case *ast.Ident:
+			if pass.TypesInfo.Uses[node] == h.object {
+				h.other = true
+			}
+		}
+		return true
+	}
+}
+
+/**
+ * checks whether an expression selects a field of the header variable
+ */
+func (h *headerVariable) isField(expr ast.Expr, pass *analysis.Pass) bool {
+	selector, ok := ast.Unparen(expr).(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := ast.Unparen(selector.X).(*ast.Ident)
+	return ok && pass.TypesInfo.Uses[ident] == h.object
+}
+
+/**
+ * checks whether the header variable is only used in ways that can be rewritten: either only field reads, or exactly
+ * one assignment to each field right after the definition
+ */
+func (h *headerVariable) replaceable() bool {
+	if h.other {
+		return false
+	}
+	if len(h.writes) == 0 {
+		return len(h.reads) > 0
+	}
+
+	// the assignments must immediately follow the definition, and set every field once
+	fields := 2
+	if h.slice {
+		fields = 3
+	}
+	if len(h.reads) != 0 || len(h.writes) != fields {
+		return false
+	}
+	index := statementIndex(h.block, h.stmt)
+	if index < 0 {
+		return false
+	}
+	seen := make(map[string]bool)
+	for i, write := range h.writes {
+		if index+1+i >= len(h.block.List) || h.block.List[index+1+i] != write {
+			return false
+		}
+		seen[ast.Unparen(write.Lhs[0]).(*ast.SelectorExpr).Sel.Name] = true
+	}
+	return seen["Data"] && seen["Len"] && (!h.slice || seen["Cap"])
+}
+
+/**
+ * checks whether all field reads of a header variable are within the given statements
+ */
+func readsWithin(header *headerVariable, stmts []*ast.AssignStmt) bool {
+	for _, read := range header.reads {
+		inside := false
+		for _, stmt := range stmts {
+			if stmt.Pos() <= read.Pos() && read.End() <= stmt.End() {
+				inside = true
+			}
+		}
+		if !inside {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+ * builds the fix that replaces a header variable. Read-only headers are replaced by len, cap and the unsafe data
+ * functions on their source, and headers that are assigned to are replaced by a call to unsafe.Slice or unsafe.String
+ * assigned to their source. The reads of other headers within the assignments are replaced as well
+ */
+func headerFix(header *headerVariable, reads []*headerVariable, pass *analysis.Pass) (analysis.SuggestedFix, bool) {
+	file := astedit.EnclosingFile(header.stmt, pass)
+	if file == nil {
+		return analysis.SuggestedFix{}, false
+	}
+	unsafeName, ok := astedit.ImportName(file, "unsafe")
+	if !ok {
+		return analysis.SuggestedFix{}, false
+	}
+	r := &rewrite{pass: pass, file: file, unsafeName: unsafeName}
+
+	var message string
+	if len(header.writes) == 0 {
+		// replace every field read, and remove the definition
+		for _, read := range header.reads {
+			r.edits = append(r.edits, analysis.TextEdit{
+				Pos: read.Pos(), End: read.End(), NewText: []byte(r.fieldRead(header, read.Sel.Name)),
+			})
+		}
+		r.remove(header.stmt)
+		message = "replace reflect header with len, cap and unsafe." + dataFunction(header)
+	} else {
+		replacement, ok := r.constructor(header, reads)
+		if !ok {
+			return analysis.SuggestedFix{}, false
+		}
+		// replace the definition and the assignments with a single assignment to the source
+		last := header.writes[len(header.writes)-1]
+		r.edits = append(r.edits, analysis.TextEdit{
+			Pos: header.stmt.Pos(), End: last.End(), NewText: []byte(header.source.Name + " = " + replacement),
+		})
+		r.removed = append(r.removed, header.stmt)
+		for _, write := range header.writes {
+			r.removed = append(r.removed, write)
+		}
+		for _, read := range reads {
+			r.remove(read.stmt)
+		}
+		message = "replace reflect header with unsafe.Slice"
+		if !header.slice {
+			message = "replace reflect header with unsafe.String"
+		}
+	}
+
+	// the fix must compile with the Go version of the module
+	if r.minVersion != "" && !astedit.ModuleSupports(pass, r.minVersion) {
+		return analysis.SuggestedFix{}, false
+	}
+
+	// finally, the reflect import might have become unused now, and so might the unsafe import if only Len and Cap
+	// were read
+	paths := []string{"reflect"}
+	if !r.usesUnsafe {
+		paths = append(paths, "unsafe")
+	}
+	r.edits = append(r.edits, astedit.RemoveUnusedImports(file, paths, r.removed, false, pass)...)
+
+	return analysis.SuggestedFix{Message: message, TextEdits: r.edits}, true
+}
+
+/**
+ * builds the call to unsafe.Slice or unsafe.String that replaces the assignments to a header variable
+ */
+func (r *rewrite) constructor(header *headerVariable, reads []*headerVariable) (string, bool) {
+	values := make(map[string]ast.Expr)
+	for _, write := range header.writes {
+		values[ast.Unparen(write.Lhs[0]).(*ast.SelectorExpr).Sel.Name] = write.Rhs[0]
+	}
+
+	// the element type of the resulting slice or string
+	elem := types.Universe.Lookup("byte").Type()
+	if header.slice {
+		elem = r.pass.TypesInfo.TypeOf(header.source).Underlying().(*types.Slice).Elem()
+	}
+	// the data pointer is taken directly from the source of another header if possible
+	data, ok := r.dataPointer(values["Data"], elem, reads)
+	if !ok {
+		return "", false
+	}
+	length := r.substitute(values["Len"], reads)
+	r.usesUnsafe = true
+
+	if !header.slice {
+		r.require(unsafeStringGoVersion)
+		return fmt.Sprintf("%s.String(%s, %s)", r.unsafeName, data, length), true
+	}
+	r.require(unsafeSliceGoVersion)
+	capacity := r.substitute(values["Cap"], reads)
+	if capacity == length {
+		return fmt.Sprintf("%s.Slice(%s, %s)", r.unsafeName, data, length), true
+	}
+	return fmt.Sprintf("%s.Slice(%s, %s)[:%s]", r.unsafeName, data, capacity, length), true
+}
+
+/**
+ * builds the pointer expression for the Data field of a new slice or string
+ */
+func (r *rewrite) dataPointer(value ast.Expr, elem types.Type, reads []*headerVariable) (string, bool) {
+	elemText, ok := astedit.TypeString(elem, r.file, r.pass)
+	if !ok {
+		return "", false
+	}
+
+	// the Data field of another header is replaced by the data pointer of its source, if the element types match
+	if selector, ok := ast.Unparen(value).(*ast.SelectorExpr); ok && selector.Sel.Name == "Data" {
+		for _, read := range reads {
+			if !read.isField(selector, r.pass) {
+				continue
+			}
+			sourceElem := types.Universe.Lookup("byte").Type()
+			if read.slice {
+				sourceElem = r.pass.TypesInfo.TypeOf(read.source).Underlying().(*types.Slice).Elem()
+			}
+			pointer := r.sourceData(read)
+			if types.Identical(sourceElem, elem) {
+				return pointer, true
+			}
+			return fmt.Sprintf("(*%s)(%s.Pointer(%s))", elemText, r.unsafeName, pointer), true
+		}
+	}
+
+	// any other value must be a pointer that is converted to uintptr right there, which is then converted to a pointer
+	// of the element type instead. An arbitrary uintptr does not keep its object alive, so converting it back to a
+	// pointer would be a misuse of unsafe.Pointer
+	conversion, ok := ast.Unparen(value).(*ast.CallExpr)
+	if !ok || len(conversion.Args) != 1 || !r.isUintptrConversion(conversion) {
+		return "", false
+	}
+	pointer, ok := ast.Unparen(conversion.Args[0]).(*ast.CallExpr)
+	if !ok || len(pointer.Args) != 1 || !resolve.IsUnsafePointerConversion(r.pass.TypesInfo, pointer) {
+		return "", false
+	}
+	return fmt.Sprintf("(*%s)(%s.Pointer(%s))", elemText, r.unsafeName, r.substitute(pointer.Args[0], reads)), true
+}
+
+/**
+ * checks whether a call expression is a conversion to uintptr
+ */
+func (r *rewrite) isUintptrConversion(call *ast.CallExpr) bool {
+	callee, ok := r.pass.TypesInfo.Types[call.Fun]
+	if !ok || !callee.IsType() {
+		return false
+	}
+	basic, ok := callee.Type.Underlying().(*types.Basic)
+	return ok && basic.Kind() == types.Uintptr
+}
+
+/**
+ * returns the replacement for reading a field of a header variable
+ */
+func (r *rewrite) fieldRead(header *headerVariable, field string) string {
+	switch field {
+	case "Data":
+		return fmt.Sprintf("uintptr(%s.Pointer(%s))", r.unsafeName, r.sourceData(header))
+	case "Cap":
+		return "cap(" + header.source.Name + ")"
+	}
+	return "len(" + header.source.Name + ")"
+}
+
+/**
+ * returns the call to unsafe.StringData or unsafe.SliceData for the source of a header variable
+ */
+func (r *rewrite) sourceData(header *headerVariable) string {
+	r.require(unsafeStringGoVersion)
+	r.usesUnsafe = true
+	return fmt.Sprintf("%s.%s(%s)", r.unsafeName, dataFunction(header), header.source.Name)
+}
+
+/**
+ * returns the source code of an expression, with the field reads of the given header variables replaced
+ */
+func (r *rewrite) substitute(expr ast.Expr, reads []*headerVariable) string {
+	tokFile := r.pass.Fset.File(expr.Pos())
+	content, err := r.pass.ReadFile(tokFile.Name())
+	if err != nil {
+		return astedit.Render(expr, r.pass)
+	}
+
+	// collect the field reads within the expression, and replace them from back to front
+	type replacement struct {
+		selector *ast.SelectorExpr
+		text     string
+	}
+	var replacements []replacement
+	for _, read := range reads {
+		for _, selector := range read.reads {
+			if expr.Pos() <= selector.Pos() && selector.End() <= expr.End() {
+				replacements = append(replacements, replacement{selector, r.fieldRead(read, selector.Sel.Name)})
+			}
+		}
+	}
+	sort.Slice(replacements, func(i, j int) bool {
+		return replacements[i].selector.Pos() > replacements[j].selector.Pos()
+	})
+
+	start := tokFile.Offset(expr.Pos())
+	text := string(content[start:tokFile.Offset(expr.End())])
+	for _, replacement := range replacements {
+		from := tokFile.Offset(replacement.selector.Pos()) - start
+		to := tokFile.Offset(replacement.selector.End()) - start
+		text = text[:from] + replacement.text + text[to:]
+	}
+	return text
+}
+
+/**
+ * raises the Go version that the rewrite needs
+ */
+func (r *rewrite) require(minimum string) {
+	if r.minVersion == "" || version.Compare(minimum, r.minVersion) > 0 {
+		r.minVersion = minimum
+	}
+}
+
+/**
+ * adds an edit that removes a statement
+ */
+func (r *rewrite) remove(stmt ast.Stmt) {
+	r.edits = append(r.edits, astedit.DeleteLines(stmt, r.pass))
+	r.removed = append(r.removed, stmt)
+}
+
+/**
+ * returns the name of the unsafe function that returns the data pointer of the source of a header
+ */
+func dataFunction(header *headerVariable) string {
+	if header.slice {
+		return "SliceData"
+	}
+	return "StringData"
+}
+
+/**
+ * returns the index of a statement in a block, or -1 if it is not part of it
+ */
+func statementIndex(block *ast.BlockStmt, stmt ast.Stmt) int {
+	for i, other := range block.List {
+		if other == stmt {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,79 @@
+package headermigration
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+)
+
+// Analyzer is a golang.org/x/tools/go/analysis style linter pass.
+// Use this with the Vet-style infrastructure.
+var Analyzer = &analysis.Analyzer{
+	Name:             "headermigration",
+	Doc:              "reports uses of the deprecated reflect.SliceHeader and reflect.StringHeader types and suggests unsafe.Slice, unsafe.String and friends instead",
+	Run:              run,
+	Requires:         []*analysis.Analyzer{inspect.Analyzer},
+	RunDespiteErrors: true,
+}
+
+/**
+ * run is the entry point to the analysis pass
+ */
+func run(pass *analysis.Pass) (interface{}, error) {
+	// get results from required inspect analyzer
+	inspectResult := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	// first, find the header variables that can be replaced, and build the fixes for them. The fixes are attached to
+	// the header type in the cast that defines the variable
+	fixes := migrationFixes(inspectResult, pass)
+
+	// then report every use of a header type, including look-alike types with the same fields
+	inspectResult.WithStack([]ast.Node{(*ast.Ident)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		ident := n.(*ast.Ident)
+		typeName, ok := pass.TypesInfo.Uses[ident].(*types.TypeName)
+		if !ok || !resolve.IsReflectHeader(typeName.Type()) {
+			return true
+		}
+
+		// report qualified identifiers like reflect.SliceHeader at the package name
+		var pos ast.Node = ident
+		if selector, ok := stack[len(stack)-2].(*ast.SelectorExpr); ok && selector.Sel == ident {
+			pos = selector
+		}
+
+		pass.Report(analysis.Diagnostic{
+			Pos:            pos.Pos(),
+			End:            pos.End(),
+			Message:        deprecationMessage(typeName),
+			SuggestedFixes: fixes[ident],
+		})
+		return true
+	})
+
+	return nil, nil
+}
+
+/**
+ * describes why a header type should not be used anymore, and what to use instead
+ */
+func deprecationMessage(typeName *types.TypeName) string {
+	replacement := "unsafe.String and unsafe.StringData"
+	header := "reflect.StringHeader"
+	if resolve.ReflectHeaderName(typeName.Type()) == "SliceHeader" {
+		replacement = "unsafe.Slice and unsafe.SliceData"
+		header = "reflect.SliceHeader"
+	}
+
+	if typeName.Pkg() != nil && typeName.Pkg().Path() == "reflect" {
+		return header + " is deprecated, use " + replacement + " instead"
+	}
+	return typeName.Name() + " has the layout of " + header + ", which is deprecated, use " + replacement + " instead"
+}
@@ -0,0 +1,42 @@
+package headermigration_test
+
+import (
+	"github.com/jlauinger/go-safer/passes/headermigration"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"path/filepath"
+	"testing"
+)
+
+func Test(t *testing.T) {
+	// use go vet infrastructure testing and supply annotated code examples
+	testdata := analysistest.TestData()
+	testPackages := []string{
+		"bad/deprecated_types",
+		"bad/length_only",
+
+		"good/unsafe_functions",
+		"good/empty_interfaces",
+	}
+	analysistest.RunWithSuggestedFixes(t, testdata, headermigration.Analyzer, testPackages...)
+}
+
+func TestModule(t *testing.T) {
+	// the fixes to unsafe.String, unsafe.StringData and unsafe.SliceData need at least go 1.20
+	testdata := filepath.Join(analysistest.TestData(), "mod")
+	testPackages := []string{
+		"./string_to_bytes",
+		"./bytes_to_string",
+		"./read_header",
+	}
+	analysistest.RunWithSuggestedFixes(t, testdata, headermigration.Analyzer, testPackages...)
+}
+
+func TestLegacyModule(t *testing.T) {
+	// with go 1.17, only unsafe.Slice is available
+	testdata := filepath.Join(analysistest.TestData(), "legacy")
+	testPackages := []string{
+		"./slice_from_pointer",
+		"./string_from_pointer",
+	}
+	analysistest.RunWithSuggestedFixes(t, testdata, headermigration.Analyzer, testPackages...)
+}
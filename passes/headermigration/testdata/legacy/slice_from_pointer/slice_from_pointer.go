@@ -0,0 +1,22 @@
+package slice_from_pointer
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+func Words(p uintptr, n int) (words []uint32) {
+	sH := (*reflect.SliceHeader)(unsafe.Pointer(&words)) // want "reflect.SliceHeader is deprecated, use unsafe.Slice and unsafe.SliceData instead"
+	sH.Data = p
+	sH.Len = n
+	sH.Cap = n
+	return
+}
+
+func ArrayWords(a *[4]uint32) (words []uint32) {
+	sH := (*reflect.SliceHeader)(unsafe.Pointer(&words)) // want "reflect.SliceHeader is deprecated, use unsafe.Slice and unsafe.SliceData instead"
+	sH.Data = uintptr(unsafe.Pointer(a))
+	sH.Len = len(a)
+	sH.Cap = len(a)
+	return
+}
@@ -0,0 +1,13 @@
+package string_from_pointer
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+func String(p uintptr, n int) (s string) {
+	sH := (*reflect.StringHeader)(unsafe.Pointer(&s)) // want "reflect.StringHeader is deprecated, use unsafe.String and unsafe.StringData instead"
+	sH.Data = p
+	sH.Len = n
+	return
+}
@@ -0,0 +1,14 @@
+package bytes_to_string
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+func UnsafeCastBytes(b []byte) (s string) {
+	bH := (*reflect.SliceHeader)(unsafe.Pointer(&b))  // want "reflect.SliceHeader is deprecated, use unsafe.Slice and unsafe.SliceData instead"
+	sH := (*reflect.StringHeader)(unsafe.Pointer(&s)) // want "reflect.StringHeader is deprecated, use unsafe.String and unsafe.StringData instead"
+	sH.Len = bH.Len - 1
+	sH.Data = bH.Data + 1
+	return
+}
@@ -0,0 +1,15 @@
+package bytes_to_string
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+func WordsToBytes(words []uint32) (b []byte) {
+	wH := (*reflect.SliceHeader)(unsafe.Pointer(&words)) // want "reflect.SliceHeader is deprecated, use unsafe.Slice and unsafe.SliceData instead"
+	bH := (*reflect.SliceHeader)(unsafe.Pointer(&b))     // want "reflect.SliceHeader is deprecated, use unsafe.Slice and unsafe.SliceData instead"
+	bH.Data = wH.Data
+	bH.Len = 4 * wH.Len
+	bH.Cap = 4 * wH.Cap
+	return
+}
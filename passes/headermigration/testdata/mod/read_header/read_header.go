@@ -0,0 +1,17 @@
+package read_header
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+func PrintHeader(s string) {
+	sH := (*reflect.StringHeader)(unsafe.Pointer(&s)) // want "reflect.StringHeader is deprecated, use unsafe.String and unsafe.StringData instead"
+	fmt.Println(sH.Data, sH.Len)
+}
+
+func PointerEscapes(b []byte) *reflect.SliceHeader { // want "reflect.SliceHeader is deprecated, use unsafe.Slice and unsafe.SliceData instead"
+	sH := (*reflect.SliceHeader)(unsafe.Pointer(&b)) // want "reflect.SliceHeader is deprecated, use unsafe.Slice and unsafe.SliceData instead"
+	return sH
+}
@@ -0,0 +1,15 @@
+package string_to_bytes
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+func UnsafeCastString(str string) (b []byte) {
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&str)) // want "reflect.StringHeader is deprecated, use unsafe.String and unsafe.StringData instead"
+	sH := (*reflect.SliceHeader)(unsafe.Pointer(&b))      // want "reflect.SliceHeader is deprecated, use unsafe.Slice and unsafe.SliceData instead"
+	sH.Data = strH.Data
+	sH.Len = strH.Len
+	sH.Cap = strH.Len
+	return
+}
@@ -0,0 +1,31 @@
+package deprecated_types
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+type MysteryType reflect.SliceHeader // want "reflect.SliceHeader is deprecated, use unsafe.Slice and unsafe.SliceData instead"
+
+type stringHeader struct {
+	Data uintptr
+	Len  int
+}
+
+func HeaderLiteral(p *byte, n int) string {
+	sH := reflect.StringHeader{Data: uintptr(unsafe.Pointer(p)), Len: n} // want "reflect.StringHeader is deprecated, use unsafe.String and unsafe.StringData instead"
+	return *(*string)(unsafe.Pointer(&sH))
+}
+
+func LookAlike(s string) uintptr {
+	return (*stringHeader)(unsafe.Pointer(&s)).Data // want "stringHeader has the layout of reflect.StringHeader, which is deprecated, use unsafe.String and unsafe.StringData instead"
+}
+
+func RenamedType(b []byte) *MysteryType { // want "MysteryType has the layout of reflect.SliceHeader, which is deprecated, use unsafe.Slice and unsafe.SliceData instead"
+	return (*MysteryType)(unsafe.Pointer(&b)) // want "MysteryType has the layout of reflect.SliceHeader, which is deprecated, use unsafe.Slice and unsafe.SliceData instead"
+}
+
+func DataWithoutVersion(b []byte) uintptr {
+	sH := (*reflect.SliceHeader)(unsafe.Pointer(&b)) // want "reflect.SliceHeader is deprecated, use unsafe.Slice and unsafe.SliceData instead"
+	return sH.Data
+}
@@ -0,0 +1,11 @@
+package length_only
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+func Capacity(b []byte) int {
+	sH := (*reflect.SliceHeader)(unsafe.Pointer(&b)) // want "reflect.SliceHeader is deprecated, use unsafe.Slice and unsafe.SliceData instead"
+	return sH.Cap - sH.Len
+}
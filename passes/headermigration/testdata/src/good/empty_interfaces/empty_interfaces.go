@@ -0,0 +1,28 @@
+package empty_interfaces
+
+import "fmt"
+
+type Box struct {
+	Value any
+	Other interface{}
+}
+
+func Describe(value any) string {
+	return fmt.Sprint(value)
+}
+
+func Collect(values ...interface{}) []interface{} {
+	return values
+}
+
+func First[T any](values []T) T {
+	return values[0]
+}
+
+func Keys[K comparable, V any](m map[K]V) []K {
+	var keys []K
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
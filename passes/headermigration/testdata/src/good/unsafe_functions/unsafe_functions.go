@@ -0,0 +1,22 @@
+package unsafe_functions
+
+import (
+	"unsafe"
+)
+
+type header struct {
+	Data uintptr
+	Size int
+}
+
+func StringToBytes(s string) []byte {
+	return unsafe.Slice(unsafe.StringData(s), len(s))
+}
+
+func BytesToString(b []byte) string {
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
+
+func DifferentFields(h *header) int {
+	return h.Size
+}
@@ -0,0 +1,246 @@
+// Package astedit contains helpers to build the text edits of suggested fixes, like adding and removing imports or
+// deleting statements.
+package astedit
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"go/version"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+/**
+ * checks whether a node lies within another node
+ */
+func Within(n ast.Node, outer ast.Node) bool {
+	return outer.Pos() <= n.Pos() && n.End() <= outer.End()
+}
+
+/**
+ * builds an edit that removes the import of a package if it is not used anymore outside of the removed nodes. If only
+ * one import is left in a group, the parentheses are removed as well, unless the fix adds imports to the file, which
+ * AddImport puts into the group
+ */
+func RemoveUnusedImport(file *ast.File, path string, removed []ast.Node, addsImports bool,
+	pass *analysis.Pass) []analysis.TextEdit {
+	return RemoveUnusedImports(file, []string{path}, removed, addsImports, pass)
+}
+
+/**
+ * builds the edits that remove the imports of several packages that are not used anymore outside of the removed nodes,
+ * like RemoveUnusedImport. The imports are removed together, so that a group is only rewritten once
+ */
+func RemoveUnusedImports(file *ast.File, paths []string, removed []ast.Node, addsImports bool,
+	pass *analysis.Pass) []analysis.TextEdit {
+	unused := make(map[string]bool)
+	for _, path := range paths {
+		unused[fmt.Sprintf("%q", path)] = true
+	}
+
+	// check for uses of the package names anywhere in the file outside of the nodes that are removed
+	for ident, object := range pass.TypesInfo.Uses {
+		pkgName, ok := object.(*types.PkgName)
+		if !ok || !Within(ident, file) {
+			continue
+		}
+		stillUsed := true
+		for _, n := range removed {
+			if Within(ident, n) {
+				stillUsed = false
+			}
+		}
+		if stillUsed {
+			delete(unused, fmt.Sprintf("%q", pkgName.Imported().Path()))
+		}
+	}
+	if len(unused) == 0 {
+		return nil
+	}
+
+	// find the import specs and remove them, or the whole declaration if no spec is left
+	var edits []analysis.TextEdit
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+		var removedSpecs []*ast.ImportSpec
+		var remaining ast.Spec
+		for _, spec := range genDecl.Specs {
+			if importSpec := spec.(*ast.ImportSpec); unused[importSpec.Path.Value] {
+				removedSpecs = append(removedSpecs, importSpec)
+			} else {
+				remaining = spec
+			}
+		}
+		switch {
+		case len(removedSpecs) == 0:
+			continue
+		case len(removedSpecs) == len(genDecl.Specs):
+			edits = append(edits, DeleteLines(genDecl, pass))
+			continue
+		case len(removedSpecs) == len(genDecl.Specs)-1 && genDecl.Lparen.IsValid() && !addsImports:
+			if edit, ok := ungroupImport(file, genDecl, remaining, pass); ok {
+				edits = append(edits, edit)
+				continue
+			}
+		}
+		for _, importSpec := range removedSpecs {
+			edits = append(edits, DeleteLines(importSpec, pass))
+		}
+	}
+	return edits
+}
+
+/**
+ * builds an edit that replaces a group of imports with a single import declaration of the one that is not removed,
+ * like import "unsafe". This fails if the group contains comments, which would be lost
+ */
+func ungroupImport(file *ast.File, genDecl *ast.GenDecl, remaining ast.Spec,
+	pass *analysis.Pass) (analysis.TextEdit, bool) {
+	for _, group := range file.Comments {
+		if Within(group, genDecl) {
+			return analysis.TextEdit{}, false
+		}
+	}
+	return analysis.TextEdit{
+		Pos:     genDecl.Pos(),
+		End:     genDecl.End(),
+		NewText: []byte("import " + Render(remaining, pass)),
+	}, true
+}
+
+/**
+ * builds an edit that removes the complete lines that a node spans
+ */
+func DeleteLines(n ast.Node, pass *analysis.Pass) analysis.TextEdit {
+	tokFile := pass.Fset.File(n.Pos())
+	startLine := tokFile.Line(n.Pos())
+	endLine := tokFile.Line(n.End())
+	end := token.Pos(tokFile.Base() + tokFile.Size())
+	if endLine < tokFile.LineCount() {
+		end = tokFile.LineStart(endLine + 1)
+	}
+	return analysis.TextEdit{Pos: tokFile.LineStart(startLine), End: end}
+}
+
+/**
+ * prints an AST node back to source code
+ */
+func Render(n ast.Node, pass *analysis.Pass) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, pass.Fset, n); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+/**
+ * returns the name under which a package is imported into a file, if it can be referred to
+ */
+func ImportName(file *ast.File, path string) (string, bool) {
+	for _, importSpec := range file.Imports {
+		if importSpec.Path.Value != fmt.Sprintf("%q", path) {
+			continue
+		}
+		if importSpec.Name == nil {
+			return path[strings.LastIndex(path, "/")+1:], true
+		}
+		if importSpec.Name.Name == "_" || importSpec.Name.Name == "." {
+			return "", false
+		}
+		return importSpec.Name.Name, true
+	}
+	return "", false
+}
+
+/**
+ * prints a type as it can be referred to in a file. This fails if the type refers to a package that is not imported
+ * into the file
+ */
+func TypeString(t types.Type, file *ast.File, pass *analysis.Pass) (string, bool) {
+	ok := true
+	text := types.TypeString(t, func(pkg *types.Package) string {
+		if pkg == pass.Pkg {
+			return ""
+		}
+		name, imported := ImportName(file, pkg.Path())
+		if !imported {
+			ok = false
+		}
+		return name
+	})
+	return text, ok
+}
+
+/**
+ * finds the file that contains a node
+ */
+func EnclosingFile(n ast.Node, pass *analysis.Pass) *ast.File {
+	for _, file := range pass.Files {
+		if file.Pos() <= n.Pos() && n.End() <= file.End() {
+			return file
+		}
+	}
+	return nil
+}
+
+/**
+ * finds the body of the innermost function on an AST stack
+ */
+func EnclosingFuncBody(stack []ast.Node) *ast.BlockStmt {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch f := stack[i].(type) {
+		case *ast.FuncDecl:
+			return f.Body
+		case *ast.FuncLit:
+			return f.Body
+		}
+	}
+	return nil
+}
+
+/**
+ * checks whether the module under analysis declares at least the given Go version. If the version is unknown, e.g.
+ * in GOPATH mode, this conservatively returns false
+ */
+func ModuleSupports(pass *analysis.Pass, minimum string) bool {
+	goVersion := pass.Pkg.GoVersion()
+	if goVersion == "" {
+		return false
+	}
+	return version.Compare(goVersion, minimum) >= 0
+}
+
+/**
+ * builds an edit that adds an import of a package to a file, into the first grouped import declaration if there is one
+ */
+func AddImport(file *ast.File, path string, pass *analysis.Pass) analysis.TextEdit {
+	quoted := fmt.Sprintf("%q", path)
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+		if !genDecl.Lparen.IsValid() {
+			// a single import without parenthesis, add a separate declaration after it
+			return analysis.TextEdit{Pos: genDecl.End(), End: genDecl.End(), NewText: []byte("\nimport " + quoted)}
+		}
+		// keep the imports sorted by inserting in front of the first import that comes after the new one
+		for _, spec := range genDecl.Specs {
+			importSpec := spec.(*ast.ImportSpec)
+			if importSpec.Path.Value > quoted {
+				return analysis.TextEdit{Pos: importSpec.Pos(), End: importSpec.Pos(), NewText: []byte(quoted + "\n\t")}
+			}
+		}
+		return analysis.TextEdit{Pos: genDecl.Rparen, End: genDecl.Rparen, NewText: []byte("\t" + quoted + "\n")}
+	}
+	// there are no imports yet, add a declaration after the package clause
+	return analysis.TextEdit{Pos: file.Name.End(), End: file.Name.End(), NewText: []byte("\n\nimport " + quoted)}
+}
@@ -0,0 +1,65 @@
+// Package layout answers questions about where values lie in memory: whether an expression addresses the bytes of a
+// buffer, and what alignment an offset guarantees.
+package layout
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+/**
+ * checks whether an expression is the address of an element of a byte slice or array, like &buf[off], and returns the
+ * buffer and the index
+ */
+func ByteElementAddress(info *types.Info, expr ast.Expr) (ast.Expr, ast.Expr, bool) {
+	unary, ok := ast.Unparen(expr).(*ast.UnaryExpr)
+	if !ok || unary.Op != token.AND {
+		return nil, nil, false
+	}
+	index, ok := ast.Unparen(unary.X).(*ast.IndexExpr)
+	if !ok {
+		return nil, nil, false
+	}
+	t := info.TypeOf(index.X)
+	if t == nil {
+		return nil, nil, false
+	}
+	if pointer, ok := t.Underlying().(*types.Pointer); ok {
+		t = pointer.Elem()
+	}
+	if !IsBytes(t) {
+		return nil, nil, false
+	}
+	return index.X, index.Index, true
+}
+
+/**
+ * checks whether a type is a slice or array of bytes
+ */
+func IsBytes(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	var elem types.Type
+	switch buffer := t.Underlying().(type) {
+	case *types.Slice:
+		elem = buffer.Elem()
+	case *types.Array:
+		elem = buffer.Elem()
+	default:
+		return false
+	}
+	basic, ok := elem.Underlying().(*types.Basic)
+	return ok && (basic.Kind() == types.Uint8 || basic.Kind() == types.Int8)
+}
+
+/**
+ * computes the greatest common divisor of two numbers, where zero is a multiple of every number
+ */
+func GCD(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,99 @@
+package resolve
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// Linkname is a go:linkname directive. Target is empty for directives that only make a local symbol available to
+// other packages
+type Linkname struct {
+	Comment *ast.Comment
+	Local   string
+	Target  string
+}
+
+/**
+ * collects the go:linkname directives in a file
+ */
+func Linknames(file *ast.File) []Linkname {
+	var linknames []Linkname
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			if !strings.HasPrefix(comment.Text, "//go:linkname ") {
+				continue
+			}
+			fields := strings.Fields(strings.TrimPrefix(comment.Text, "//go:linkname "))
+			if len(fields) == 0 {
+				continue
+			}
+			linkname := Linkname{Comment: comment, Local: fields[0]}
+			if len(fields) > 1 {
+				linkname.Target = fields[1]
+			}
+			linknames = append(linknames, linkname)
+		}
+	}
+	return linknames
+}
+
+/**
+ * splits a linker symbol like runtime.nanotime or runtime.(*Frames).Next into its package path and name
+ */
+func SplitSymbol(symbol string) (string, string) {
+	slash := strings.LastIndex(symbol, "/")
+	dot := strings.Index(symbol[slash+1:], ".")
+	if dot < 0 {
+		return "", symbol
+	}
+	dot += slash + 1
+	return symbol[:dot], symbol[dot+1:]
+}
+
+/**
+ * checks whether a package path is an internal package, which can only be imported from within its parent
+ */
+func IsInternalPackage(path string) bool {
+	return path == "internal" || strings.HasPrefix(path, "internal/") || strings.HasSuffix(path, "/internal") ||
+		strings.Contains(path, "/internal/")
+}
+
+/**
+ * checks whether a go:linkname directive pushes a local function to its target instead of pulling the target in,
+ * which is the case if the local function is declared with a body
+ */
+func IsPushedLinkname(linkname Linkname, files []*ast.File, pkg *types.Package) bool {
+	function, ok := pkg.Scope().Lookup(linkname.Local).(*types.Func)
+	if !ok {
+		return false
+	}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && decl.Name.Pos() == function.Pos() {
+				return decl.Body != nil
+			}
+		}
+	}
+	return false
+}
+
+/**
+ * checks whether a file was generated by cgo, which links its own helpers into the runtime with go:linkname
+ */
+func IsCgoGenerated(file *ast.File) bool {
+	if !ast.IsGenerated(file) {
+		return false
+	}
+	for _, group := range file.Comments {
+		if group.Pos() > file.Package {
+			break
+		}
+		for _, comment := range group.List {
+			if strings.HasPrefix(comment.Text, "// Code generated by cmd/cgo") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+// Package resolve identifies uses of unsafe and standard library identifiers through the type information of a
+// package, rather than by comparing identifier names. This makes renamed imports, dot imports and shadowing local
+// identifiers work correctly. Reflect headers are recognised by their structure, so that look-alike declarations are
+// found as well. The linker symbols that go:linkname directives refer to are parsed here, too.
+package resolve
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+/**
+ * checks whether an expression refers to the object with the given name in the package with the given import path,
+ * e.g. reflect.SliceHeader, r.SliceHeader with a renamed import, or SliceHeader with a dot import
+ */
+func IsPackageObject(info *types.Info, expr ast.Expr, path, name string) bool {
+	// find the identifier that refers to the object, which is the selector in a qualified identifier
+	var ident *ast.Ident
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		ident = e
+	case *ast.SelectorExpr:
+		ident = e.Sel
+	default:
+		return false
+	}
+
+	// then, check the object it refers to
+	object := info.Uses[ident]
+	return object != nil && object.Pkg() != nil && object.Pkg().Path() == path && object.Name() == name
+}
+
+/**
+ * checks whether an expression denotes the type unsafe.Pointer
+ */
+func IsUnsafePointer(info *types.Info, expr ast.Expr) bool {
+	tv, ok := info.Types[expr]
+	return ok && tv.IsType() && types.Identical(tv.Type, types.Typ[types.UnsafePointer])
+}
+
+/**
+ * checks whether a call expression is a conversion to unsafe.Pointer
+ */
+func IsUnsafePointerConversion(info *types.Info, call *ast.CallExpr) bool {
+	return len(call.Args) == 1 && IsUnsafePointer(info, call.Fun)
+}
+
+/**
+ * checks whether a type is a reflect header or a pointer to one. Besides reflect.SliceHeader and reflect.StringHeader,
+ * this matches every struct with the same fields, like a copy of the declaration in another package
+ */
+func IsReflectHeader(t types.Type) bool {
+	// filter out possible parsing errors / invalid types
+	if t.String() == "invalid type" {
+		return false
+	}
+
+	// make up the basic structure of slice and string headers to compare to
+	sliceHeaderType := types.NewStruct([]*types.Var{
+		types.NewVar(token.NoPos, nil, "Data", types.Typ[types.Uintptr]),
+		types.NewVar(token.NoPos, nil, "Len", types.Typ[types.Int]),
+		types.NewVar(token.NoPos, nil, "Cap", types.Typ[types.Int]),
+	}, nil)
+	stringHeaderType := types.NewStruct([]*types.Var{
+		types.NewVar(token.NoPos, nil, "Data", types.Typ[types.Uintptr]),
+		types.NewVar(token.NoPos, nil, "Len", types.Typ[types.Int]),
+	}, nil)
+
+	// find the underlying type, taking care of a possible pointer type
+	var effectiveType types.Type
+	pt, ok := t.Underlying().(*types.Pointer)
+	if ok {
+		effectiveType = pt.Elem().Underlying()
+	} else {
+		effectiveType = t.Underlying()
+	}
+
+	// only structs can have the layout of a header. Interfaces, including the constraints of type parameters, would
+	// accept a header as well, but they do not share its layout
+	if _, ok := effectiveType.(*types.Struct); !ok {
+		return false
+	}
+	return types.Identical(sliceHeaderType, effectiveType) || types.Identical(stringHeaderType, effectiveType)
+}
+
+/**
+ * returns SliceHeader or StringHeader if a type is the respective reflect header type, a struct with the same fields
+ * like type MysteryType reflect.SliceHeader, or a pointer to one of them, and an empty string otherwise
+ */
+func ReflectHeaderName(t types.Type) string {
+	if t == nil || !IsReflectHeader(t) {
+		return ""
+	}
+	if pointer, ok := t.Underlying().(*types.Pointer); ok {
+		t = pointer.Elem()
+	}
+	// slice headers have a Cap field in addition to the fields of string headers
+	if t.Underlying().(*types.Struct).NumFields() == 3 {
+		return "SliceHeader"
+	}
+	return "StringHeader"
+}
+
+/**
+ * checks whether a field address refers to the Data field of reflect.SliceHeader, reflect.StringHeader, or a struct
+ * with the same fields
+ */
+func IsHeaderDataField(fieldAddr *ssa.FieldAddr) bool {
+	pointer, ok := fieldAddr.X.Type().Underlying().(*types.Pointer)
+	if !ok || !IsReflectHeader(pointer.Elem()) {
+		return false
+	}
+	return pointer.Elem().Underlying().(*types.Struct).Field(fieldAddr.Field).Name() == "Data"
+}
+
+/**
+ * checks whether a type depends on a type parameter, like T or a struct with a field of type []T. types.Sizes panics on
+ * such types, because their layout is only known once they are instantiated
+ */
+func ContainsTypeParam(t types.Type) bool {
+	return containsTypeParam(t, make(map[types.Type]bool))
+}
+
+func containsTypeParam(t types.Type, visited map[types.Type]bool) bool {
+	// named types can refer to themselves through pointers
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+
+	switch u := types.Unalias(t).(type) {
+	case *types.TypeParam:
+		return true
+	case *types.Named:
+		for i := 0; i < u.TypeArgs().Len(); i++ {
+			if containsTypeParam(u.TypeArgs().At(i), visited) {
+				return true
+			}
+		}
+		return containsTypeParam(u.Underlying(), visited)
+	case *types.Pointer:
+		return containsTypeParam(u.Elem(), visited)
+	case *types.Slice:
+		return containsTypeParam(u.Elem(), visited)
+	case *types.Array:
+		return containsTypeParam(u.Elem(), visited)
+	case *types.Map:
+		return containsTypeParam(u.Key(), visited) || containsTypeParam(u.Elem(), visited)
+	case *types.Chan:
+		return containsTypeParam(u.Elem(), visited)
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if containsTypeParam(u.Field(i).Type(), visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/**
+ * checks whether a type is unsafe.Pointer
+ */
+func IsUnsafePointerType(t types.Type) bool {
+	return t != nil && types.Identical(t.Underlying(), types.Typ[types.UnsafePointer])
+}
@@ -0,0 +1,199 @@
+// Package stdsource reads the declarations of standard library packages from their source in GOROOT, as far as
+// go:linkname is concerned. Packages are parsed once and then cached, because the runtime is needed for every analyzed
+// package.
+package stdsource
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+)
+
+// Package is what the source of a standard library package declares
+type Package struct {
+	Fset *token.FileSet
+	// Funcs are the function declarations, keyed by name, or by (*T).M and T.M for methods
+	Funcs map[string]*ast.FuncDecl
+	// Vars are the names of package-level variables
+	Vars map[string]bool
+	// Assembly are the names of functions implemented in assembly
+	Assembly map[string]bool
+	// Pushed are the full symbols that the package makes available with go:linkname, either its own symbols or ones
+	// that it provides for other packages
+	Pushed map[string]bool
+}
+
+var (
+	// packages caches the parsed packages by GOROOT and path. A nil entry means that the package does not exist
+	packages   = make(map[string]*Package)
+	packagesMu sync.Mutex
+
+	// assemblyFunction matches the declaration of a function in a Go assembly file, like TEXT ·nanotime(SB),NOSPLIT,$0
+	// or TEXT runtime·memequal<ABIInternal>(SB),NOSPLIT,$0-25. The package qualifier spells slashes as ∕
+	assemblyFunction = regexp.MustCompile(`TEXT\s+([\w.∕]*)·(\w+)(?:<[^>]*>)?\(SB\)`)
+)
+
+/**
+ * loads the declarations of a standard library package from a GOROOT, or from the GOROOT that go-safer runs with if it
+ * is empty. Only the files that are built for the current GOOS and GOARCH are used. It returns nil if there is no such
+ * package
+ */
+func Load(goroot string, path string) *Package {
+	context := build.Default
+	if goroot != "" {
+		context.GOROOT = goroot
+	}
+
+	packagesMu.Lock()
+	defer packagesMu.Unlock()
+
+	key := context.GOROOT + "|" + path
+	if pkg, ok := packages[key]; ok {
+		return pkg
+	}
+	pkg := parse(&context, path)
+	packages[key] = pkg
+	return pkg
+}
+
+/**
+ * checks whether a package path belongs to the standard library of a GOROOT, or of the GOROOT that go-safer runs with
+ * if it is empty. The package itself does not need to exist, because it might have been moved, but the top-level
+ * directory of its path must
+ */
+func IsStandardLibrary(goroot string, path string) bool {
+	if goroot == "" {
+		goroot = build.Default.GOROOT
+	}
+	first, _, _ := strings.Cut(path, "/")
+	if path == "" || strings.Contains(first, ".") {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(goroot, "src", first))
+	return err == nil && info.IsDir()
+}
+
+/**
+ * checks whether a package path belongs to the runtime, reflect, or an internal package of the standard library of a
+ * GOROOT
+ */
+func IsRuntimeInternal(goroot string, path string) bool {
+	if path == "runtime" || path == "reflect" || strings.HasPrefix(path, "runtime/") {
+		return true
+	}
+	return IsStandardLibrary(goroot, path) && resolve.IsInternalPackage(path)
+}
+
+/**
+ * checks whether a symbol is kept available for go:linkname, either by its own package or by the runtime on behalf of
+ * another package
+ */
+func IsPushed(goroot string, symbol string) bool {
+	path, _ := resolve.SplitSymbol(symbol)
+	if pkg := Load(goroot, path); pkg != nil && pkg.Pushed[symbol] {
+		return true
+	}
+	runtime := Load(goroot, "runtime")
+	return runtime != nil && runtime.Pushed[symbol]
+}
+
+/**
+ * parses the Go and assembly files of a standard library package
+ */
+func parse(context *build.Context, path string) *Package {
+	dir := filepath.Join(context.GOROOT, "src", filepath.FromSlash(path))
+	files, err := context.ImportDir(dir, 0)
+	if err != nil {
+		return nil
+	}
+
+	pkg := &Package{
+		Fset:     token.NewFileSet(),
+		Funcs:    make(map[string]*ast.FuncDecl),
+		Vars:     make(map[string]bool),
+		Assembly: make(map[string]bool),
+		Pushed:   make(map[string]bool),
+	}
+	for _, name := range files.GoFiles {
+		file, err := parser.ParseFile(pkg.Fset, filepath.Join(dir, name), nil,
+			parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				pkg.Funcs[funcKey(decl)] = decl
+			case *ast.GenDecl:
+				if decl.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range decl.Specs {
+					for _, ident := range spec.(*ast.ValueSpec).Names {
+						pkg.Vars[ident.Name] = true
+					}
+				}
+			}
+		}
+		for _, linkname := range resolve.Linknames(file) {
+			if linkname.Target == "" {
+				pkg.Pushed[path+"."+linkname.Local] = true
+			} else if targetPath, _ := resolve.SplitSymbol(linkname.Target); targetPath != path {
+				pkg.Pushed[linkname.Target] = true
+			}
+		}
+	}
+	for _, name := range files.SFiles {
+		source, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		for _, match := range assemblyFunction.FindAllSubmatch(source, -1) {
+			// functions qualified with another package belong to that package
+			if qualifier := string(match[1]); qualifier != "" && qualifier != strings.ReplaceAll(path, "/", "∕") {
+				continue
+			}
+			pkg.Assembly[string(match[2])] = true
+		}
+	}
+	return pkg
+}
+
+/**
+ * returns the name that the linker uses for a function declaration within its package, like nanotime, (*Frames).Next
+ * or Time.Unix
+ */
+func funcKey(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return decl.Name.Name
+	}
+	recv := decl.Recv.List[0].Type
+	pointer := false
+	if star, ok := recv.(*ast.StarExpr); ok {
+		pointer = true
+		recv = star.X
+	}
+	// type parameters of generic receivers are not part of the name
+	switch generic := recv.(type) {
+	case *ast.IndexExpr:
+		recv = generic.X
+	case *ast.IndexListExpr:
+		recv = generic.X
+	}
+	ident, ok := recv.(*ast.Ident)
+	if !ok {
+		return decl.Name.Name
+	}
+	if pointer {
+		return "(*" + ident.Name + ")." + decl.Name.Name
+	}
+	return ident.Name + "." + decl.Name.Name
+}
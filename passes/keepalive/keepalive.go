@@ -0,0 +1,249 @@
+package keepalive
+
+import (
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+)
+
+// Analyzer is a golang.org/x/tools/go/analysis style linter pass.
+// Use this with the Vet-style infrastructure.
+var Analyzer = &analysis.Analyzer{
+	Name:     "keepalive",
+	Doc:      "reports reflect headers whose Data source may be collected before the header is converted to a slice or string",
+	Run:      run,
+	Requires: []*analysis.Analyzer{buildssa.Analyzer},
+}
+
+// dataCopy is an assignment of the Data field of a real slice or string header to the Data field of another header
+type dataCopy struct {
+	store *ssa.Store
+	// source is the slice or string variable, or the pointer to it, that the Data field is copied from
+	source ssa.Value
+	// header is the header variable that the Data field is copied to
+	header *ssa.Alloc
+}
+
+/**
+ * run is the entry point to the analysis pass
+ */
+func run(pass *analysis.Pass) (interface{}, error) {
+	// get results from required SSA analyzer
+	ssaResult := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+
+	for _, function := range ssaResult.SrcFuncs {
+		for _, copied := range dataCopies(function) {
+			// every conversion of the header to a slice or string must be followed by a use of the source object,
+			// otherwise the garbage collector could collect it before the resulting slice or string refers to it
+			for _, conversion := range headerConversions(copied.header, function) {
+				if usedOnAllPathsAfter(conversion, copied.source) {
+					continue
+				}
+				pass.Reportf(copied.store.Pos(), "%s may be collected between copying its Data field at line %d and "+
+					"converting %s to a %s at line %d, because it is not used afterwards; add "+
+					"runtime.KeepAlive(%s) after the conversion", name(copied.source),
+					pass.Fset.Position(copied.store.Pos()).Line, name(copied.header), kind(conversion.Type()),
+					pass.Fset.Position(conversion.Pos()).Line, name(copied.source))
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+/**
+ * finds the assignments in a function that copy the Data field of a header derived from a real slice or string to the
+ * Data field of a header variable
+ */
+func dataCopies(function *ssa.Function) []dataCopy {
+	var copies []dataCopy
+	for _, block := range function.Blocks {
+		for _, instr := range block.Instrs {
+			store, ok := instr.(*ssa.Store)
+			if !ok {
+				continue
+			}
+			// the destination must be the Data field of a header variable, not of a header cast from a real slice
+			fieldAddr, ok := store.Addr.(*ssa.FieldAddr)
+			if !ok || !resolve.IsHeaderDataField(fieldAddr) {
+				continue
+			}
+			header, ok := root(fieldAddr.X).(*ssa.Alloc)
+			if !ok || !resolve.IsReflectHeader(header.Type().Underlying().(*types.Pointer).Elem()) {
+				continue
+			}
+			// the value must be loaded from the Data field of a header cast from a real slice or string
+			source := dataSource(store.Val)
+			if source == nil {
+				continue
+			}
+			if store.Pos() == token.NoPos {
+				continue
+			}
+			copies = append(copies, dataCopy{store: store, source: source, header: header})
+		}
+	}
+	return copies
+}
+
+/**
+ * follows a value that is stored to a Data field back to the real slice or string whose header it was loaded from, and
+ * returns the variable or pointer of that slice or string. Returns nil for other values
+ */
+func dataSource(value ssa.Value) ssa.Value {
+	switch v := value.(type) {
+	case *ssa.UnOp:
+		fieldAddr, ok := v.X.(*ssa.FieldAddr)
+		if v.Op != token.MUL || !ok || !resolve.IsHeaderDataField(fieldAddr) {
+			return nil
+		}
+		source := root(fieldAddr.X)
+		pointer, ok := source.Type().Underlying().(*types.Pointer)
+		if !ok || !isSliceOrString(pointer.Elem()) {
+			return nil
+		}
+		return source
+	case *ssa.BinOp:
+		// offsets added to the Data field keep the source
+		if v.Op == token.ADD || v.Op == token.SUB {
+			return dataSource(v.X)
+		}
+	case *ssa.Convert:
+		return dataSource(v.X)
+	}
+	return nil
+}
+
+/**
+ * finds the conversions of a header variable to a slice or string, like *(*[]byte)(unsafe.Pointer(&h))
+ */
+func headerConversions(header *ssa.Alloc, function *ssa.Function) []*ssa.UnOp {
+	var conversions []*ssa.UnOp
+	for _, block := range function.Blocks {
+		for _, instr := range block.Instrs {
+			load, ok := instr.(*ssa.UnOp)
+			if !ok || load.Op != token.MUL || load.X == header || root(load.X) != header {
+				continue
+			}
+			if isSliceOrString(load.Type()) {
+				conversions = append(conversions, load)
+			}
+		}
+	}
+	return conversions
+}
+
+/**
+ * checks whether a value is used after an instruction on every path through the function
+ */
+func usedOnAllPathsAfter(instr ssa.Instruction, value ssa.Value) bool {
+	// collect the instructions that use the value
+	uses := make(map[ssa.Instruction]bool)
+	for _, referrer := range *value.Referrers() {
+		if load, ok := referrer.(*ssa.UnOp); ok && load.Op == token.MUL && len(*load.Referrers()) == 0 {
+			// loads whose result is not used can be removed by the compiler
+			continue
+		}
+		uses[referrer] = true
+	}
+
+	// check the rest of the block of the instruction
+	block := instr.Block()
+	after := false
+	for _, other := range block.Instrs {
+		if other == instr {
+			after = true
+		} else if after && uses[other] {
+			return true
+		}
+	}
+
+	// then every path through the successors must use the value before leaving the function
+	visited := make(map[*ssa.BasicBlock]bool)
+	var used func(block *ssa.BasicBlock) bool
+	used = func(block *ssa.BasicBlock) bool {
+		if visited[block] {
+			// loops are used on the path that leaves them
+			return true
+		}
+		visited[block] = true
+		for _, other := range block.Instrs {
+			if uses[other] {
+				return true
+			}
+		}
+		if len(block.Succs) == 0 {
+			return false
+		}
+		for _, successor := range block.Succs {
+			if !used(successor) {
+				return false
+			}
+		}
+		return true
+	}
+	if len(block.Succs) == 0 {
+		return false
+	}
+	for _, successor := range block.Succs {
+		if !used(successor) {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+ * follows a pointer back through conversions to the value it was derived from
+ */
+func root(value ssa.Value) ssa.Value {
+	switch v := value.(type) {
+	case *ssa.Convert:
+		return root(v.X)
+	case *ssa.ChangeType:
+		return root(v.X)
+	}
+	return value
+}
+
+/**
+ * checks whether a type is a slice or string type
+ */
+func isSliceOrString(t types.Type) bool {
+	switch t := t.Underlying().(type) {
+	case *types.Slice:
+		return true
+	case *types.Basic:
+		return t.Info()&types.IsString != 0
+	}
+	return false
+}
+
+/**
+ * returns the kind of a slice or string type for messages
+ */
+func kind(t types.Type) string {
+	if _, ok := t.Underlying().(*types.Slice); ok {
+		return "slice"
+	}
+	return "string"
+}
+
+/**
+ * returns the name of a variable or parameter for messages
+ */
+func name(value ssa.Value) string {
+	if alloc, ok := value.(*ssa.Alloc); ok {
+		// composite literals and new do not have a variable name
+		if alloc.Comment == "complit" || alloc.Comment == "new" {
+			return "the header"
+		}
+		return alloc.Comment
+	}
+	return value.Name()
+}
@@ -0,0 +1,18 @@
+package keepalive_test
+
+import (
+	"github.com/jlauinger/go-safer/passes/keepalive"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"testing"
+)
+
+func Test(t *testing.T) {
+	// use go vet infrastructure testing and supply annotated code examples
+	testdata := analysistest.TestData()
+	testPackages := []string{
+		"bad/missing_keepalive",
+
+		"good/kept_alive",
+	}
+	analysistest.Run(t, testdata, keepalive.Analyzer, testPackages...)
+}
@@ -0,0 +1,57 @@
+package missing_keepalive
+
+import (
+	"reflect"
+	"runtime"
+	"unsafe"
+)
+
+func NoKeepAlive(str string) []byte {
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+	var sH reflect.SliceHeader
+	sH.Data = strH.Data // want "str may be collected between copying its Data field at line 12 and converting sH to a slice at line 15, because it is not used afterwards; add runtime.KeepAlive\\(str\\) after the conversion"
+	sH.Len = strH.Len
+	sH.Cap = strH.Len
+	return *(*[]byte)(unsafe.Pointer(&sH))
+}
+
+func KeepAliveBeforeConversion(str string) []byte {
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+	var sH reflect.SliceHeader
+	sH.Data = strH.Data // want "str may be collected between copying its Data field at line 21 and converting sH to a slice at line 25"
+	sH.Len = strH.Len
+	sH.Cap = strH.Len
+	runtime.KeepAlive(str)
+	return *(*[]byte)(unsafe.Pointer(&sH))
+}
+
+func KeepAliveInOneBranch(str string, keep bool) []byte {
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+	sH := &reflect.SliceHeader{Len: strH.Len, Cap: strH.Len}
+	sH.Data = strH.Data // want "str may be collected between copying its Data field at line 31 and converting the header to a slice at line 32"
+	b := *(*[]byte)(unsafe.Pointer(sH))
+	if keep {
+		runtime.KeepAlive(str)
+	}
+	return b
+}
+
+func CompositeLiteral(b []byte) string {
+	bH := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	sH := reflect.StringHeader{
+		Data: bH.Data + 1, // want "b may be collected between copying its Data field at line 42 and converting sH to a string at line 45"
+		Len:  bH.Len - 1,
+	}
+	return *(*string)(unsafe.Pointer(&sH))
+}
+
+type MysteryType reflect.SliceHeader
+
+func LookAlikeHeader(str string) []byte {
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+	var sH MysteryType
+	sH.Data = strH.Data // want "str may be collected between copying its Data field at line 53 and converting sH to a slice at line 56"
+	sH.Len = strH.Len
+	sH.Cap = strH.Len
+	return *(*[]byte)(unsafe.Pointer(&sH))
+}
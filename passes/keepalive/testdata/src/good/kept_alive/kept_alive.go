@@ -0,0 +1,50 @@
+package kept_alive
+
+import (
+	"reflect"
+	"runtime"
+	"unsafe"
+)
+
+func KeepAliveAfterConversion(str string) []byte {
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+	var sH reflect.SliceHeader
+	sH.Data = strH.Data
+	sH.Len = strH.Len
+	sH.Cap = strH.Len
+	b := *(*[]byte)(unsafe.Pointer(&sH))
+	runtime.KeepAlive(str)
+	return b
+}
+
+func UsedAfterConversion(str string) ([]byte, int) {
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+	var sH reflect.SliceHeader
+	sH.Data = strH.Data
+	sH.Len = strH.Len
+	sH.Cap = strH.Len
+	b := *(*[]byte)(unsafe.Pointer(&sH))
+	return b, len(str)
+}
+
+func KeepAliveInAllBranches(str string, short bool) []byte {
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+	sH := &reflect.SliceHeader{Len: strH.Len, Cap: strH.Len}
+	sH.Data = strH.Data
+	b := *(*[]byte)(unsafe.Pointer(sH))
+	if short {
+		runtime.KeepAlive(str)
+		return b[:1]
+	}
+	runtime.KeepAlive(str)
+	return b
+}
+
+func CastFromRealSlice(str string) (b []byte) {
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+	sH := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	sH.Data = strH.Data
+	sH.Len = strH.Len
+	sH.Cap = strH.Len
+	return
+}
@@ -0,0 +1,303 @@
+package linkname
+
+import (
+	"bytes"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+	"github.com/jlauinger/go-safer/passes/internal/stdsource"
+)
+
+// Analyzer is a golang.org/x/tools/go/analysis style linter pass.
+// Use this with the Vet-style infrastructure.
+var Analyzer = &analysis.Analyzer{
+	Name:             "linkname",
+	Doc:              "reports go:linkname directives whose target is missing, has a different signature, or is not kept stable",
+	Run:              run,
+	RunDespiteErrors: true,
+	ResultType:       reflect.TypeOf(Inventory{}),
+}
+
+// goroot is the GOROOT whose standard library source the targets are resolved against. It defaults to the GOROOT that
+// go-safer runs with
+var goroot string
+
+func init() {
+	Analyzer.Flags.StringVar(&goroot, "goroot", "",
+		"GOROOT to resolve go:linkname targets against, instead of the one go-safer runs with")
+}
+
+// Status is the result of resolving the target of a go:linkname directive against the standard library in GOROOT
+type Status int
+
+const (
+	// Unchecked links have no target, or a target outside of the standard library that cannot be resolved
+	Unchecked Status = iota
+	// Public links refer to an exported symbol of a public standard library package
+	Public
+	// Stable links refer to an internal symbol that its package keeps available for go:linkname
+	Stable
+	// Internal links refer to an internal symbol that may change in any Go release
+	Internal
+	// Missing links refer to a symbol that does not exist in the standard library
+	Missing
+	// Mismatched links refer to a symbol whose signature differs from the local declaration
+	Mismatched
+)
+
+// Link is a go:linkname directive together with the result of resolving its target
+type Link struct {
+	resolve.Linkname
+	Status Status
+}
+
+// Inventory lists the go:linkname directives of a package in the order they appear in. It is the result of the
+// analysis pass
+type Inventory []Link
+
+/**
+ * run is the entry point to the analysis pass
+ */
+func run(pass *analysis.Pass) (interface{}, error) {
+	var inventory Inventory
+
+	// go:linkname directives are comments, so they are not visited by the inspector
+	for _, file := range pass.Files {
+		// cgo links its own helpers into the runtime, with signatures that only need to agree with the ABI
+		if resolve.IsCgoGenerated(file) {
+			continue
+		}
+		for _, linkname := range resolve.Linknames(file) {
+			inventory = append(inventory, Link{Linkname: linkname, Status: check(linkname, pass)})
+		}
+	}
+
+	return inventory, nil
+}
+
+/**
+ * resolves the target of a go:linkname directive, and reports it if it is missing, has a different signature than the
+ * local declaration, or is an internal symbol that is not kept stable
+ */
+func check(linkname resolve.Linkname, pass *analysis.Pass) Status {
+	path, name := resolve.SplitSymbol(linkname.Target)
+	if linkname.Target == "" || path == pass.Pkg.Path() || !isStandardLibrary(path, pass) {
+		return Unchecked
+	}
+
+	// a local declaration with a body pushes its own symbol to the target instead of pulling the target in
+	if strings.HasPrefix(linkname.Local, "_cgo_") || resolve.IsPushedLinkname(linkname, pass.Files, pass.Pkg) {
+		return Unchecked
+	}
+
+	// the package itself might have been moved, like runtime/internal/atomic to internal/runtime/atomic in Go 1.23
+	pkg := stdsource.Load(goroot, path)
+	if pkg == nil {
+		pass.Reportf(linkname.Comment.Pos(), "go:linkname target %s does not exist, because there is no package %s "+
+			"in the standard library of GOROOT", linkname.Target, path)
+		return Missing
+	}
+
+	// symbols can also be provided by the runtime on behalf of another package
+	pushed := stdsource.IsPushed(goroot, linkname.Target)
+
+	decl, isFunc := pkg.Funcs[name]
+	if !isFunc && !pkg.Vars[name] && !pkg.Assembly[name] && !pushed {
+		pass.Reportf(linkname.Comment.Pos(), "go:linkname target %s does not exist in the standard library of GOROOT",
+			linkname.Target)
+		return Missing
+	}
+
+	// the local declaration must have the same kind and signature as the target
+	if local := pass.Pkg.Scope().Lookup(linkname.Local); local != nil {
+		if message := compareDeclarations(local, decl, isFunc, pkg.Vars[name], pkg.Fset); message != "" {
+			pass.Reportf(linkname.Comment.Pos(), "go:linkname target %s %s", linkname.Target, message)
+			return Mismatched
+		}
+	}
+
+	if pushed {
+		return Stable
+	}
+	if isExportedSymbol(name) && !resolve.IsInternalPackage(path) {
+		return Public
+	}
+	pass.Reportf(linkname.Comment.Pos(), "go:linkname target %s is internal to package %s and not kept available "+
+		"for go:linkname, so it may change or be blocked by the linker in any Go release", linkname.Target, path)
+	return Internal
+}
+
+/**
+ * checks whether a package path belongs to the standard library of GOROOT. Packages of the module under analysis are
+ * never part of it, even if their path does not start with a domain name
+ */
+func isStandardLibrary(path string, pass *analysis.Pass) bool {
+	if module := pass.Module; module != nil && (path == module.Path || strings.HasPrefix(path, module.Path+"/")) {
+		return false
+	}
+	return stdsource.IsStandardLibrary(goroot, path)
+}
+
+/**
+ * compares a local declaration with the declaration of the target in GOROOT, and describes the difference. It
+ * returns an empty string if they match
+ */
+func compareDeclarations(local types.Object, decl *ast.FuncDecl, isFunc, isVar bool, fset *token.FileSet) string {
+	localFunc, localIsFunc := local.(*types.Func)
+	switch {
+	case localIsFunc && isVar:
+		return "is a variable, but " + local.Name() + " is declared as a function"
+	case !localIsFunc && isFunc:
+		return "is a function, but " + local.Name() + " is not"
+	case !localIsFunc || !isFunc:
+		return ""
+	}
+
+	signature := localFunc.Type().(*types.Signature)
+	localParams, localResults := tupleKinds(signature.Params()), tupleKinds(signature.Results())
+	// the receiver of a method is passed as its first parameter
+	targetParams := append(fieldKinds(decl.Recv), fieldKinds(decl.Type.Params)...)
+	targetResults := fieldKinds(decl.Type.Results)
+
+	if kindsMatch(localParams, targetParams) && kindsMatch(localResults, targetResults) {
+		return ""
+	}
+	return "is declared as " + renderSignature(decl, fset) + " in GOROOT, but " + local.Name() + " is declared as " +
+		types.TypeString(signature, types.RelativeTo(local.Pkg()))
+}
+
+/**
+ * checks whether two lists of parameter kinds match. Empty kinds are unknown and match every kind
+ */
+func kindsMatch(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != "" && b[i] != "" && a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+ * describes the kinds of the parameters or results of a local function
+ */
+func tupleKinds(tuple *types.Tuple) []string {
+	kinds := make([]string, tuple.Len())
+	for i := range kinds {
+		kinds[i] = typeKind(tuple.At(i).Type())
+	}
+	return kinds
+}
+
+/**
+ * describes the kind of a type in a way that can be compared with the syntax of a type in another package: basic types
+ * by their name, and all kinds of pointers, slices and interfaces alike. Other types are unknown
+ */
+func typeKind(t types.Type) string {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		if u.Kind() == types.UnsafePointer {
+			return "pointer"
+		}
+		return types.Typ[u.Kind()].Name()
+	case *types.Pointer, *types.Map, *types.Chan, *types.Signature:
+		return "pointer"
+	case *types.Slice:
+		return "slice"
+	case *types.Interface:
+		return "interface"
+	}
+	return ""
+}
+
+/**
+ * describes the kinds of the fields in a parameter, result or receiver list of a declaration in GOROOT
+ */
+func fieldKinds(fields *ast.FieldList) []string {
+	var kinds []string
+	if fields == nil {
+		return kinds
+	}
+	for _, field := range fields.List {
+		kind := exprKind(field.Type)
+		// a field can declare several parameters of the same type
+		for i := 0; i < max(len(field.Names), 1); i++ {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+/**
+ * describes the kind of a type expression without type information, like typeKind does for types. Named types
+ * declared in the standard library package are unknown
+ */
+func exprKind(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return exprKind(e.X)
+	case *ast.Ident:
+		switch object := types.Universe.Lookup(e.Name).(type) {
+		case *types.TypeName:
+			return typeKind(object.Type())
+		}
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok && x.Name == "unsafe" && e.Sel.Name == "Pointer" {
+			return "pointer"
+		}
+	case *ast.StarExpr, *ast.MapType, *ast.ChanType, *ast.FuncType:
+		return "pointer"
+	case *ast.ArrayType:
+		if e.Len == nil {
+			return "slice"
+		}
+	case *ast.Ellipsis:
+		return "slice"
+	case *ast.InterfaceType:
+		return "interface"
+	}
+	return ""
+}
+
+/**
+ * prints the signature of a declaration in GOROOT like a function type, with the receiver of a method as the first
+ * parameter
+ */
+func renderSignature(decl *ast.FuncDecl, fset *token.FileSet) string {
+	funcType := *decl.Type
+	if decl.Recv != nil {
+		params := &ast.FieldList{List: append(append([]*ast.Field{}, decl.Recv.List...), decl.Type.Params.List...)}
+		funcType.Params = params
+	}
+	var buffer bytes.Buffer
+	if err := printer.Fprint(&buffer, fset, &funcType); err != nil {
+		return decl.Name.Name
+	}
+	return buffer.String()
+}
+
+/**
+ * checks whether the name of a symbol within its package is exported, which for methods requires both the type and
+ * the method to be exported
+ */
+func isExportedSymbol(name string) bool {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '(' || r == '*' || r == ')' || r == '.'
+	})
+	for _, part := range parts {
+		if !token.IsExported(part) {
+			return false
+		}
+	}
+	return len(parts) > 0
+}
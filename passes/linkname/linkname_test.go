@@ -0,0 +1,71 @@
+package linkname_test
+
+import (
+	"github.com/jlauinger/go-safer/passes/linkname"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"path/filepath"
+	"testing"
+)
+
+func Test(t *testing.T) {
+	// use go vet infrastructure testing and supply annotated code examples
+	testdata := analysistest.TestData()
+	setGoroot(t, filepath.Join(testdata, "goroot"))
+	testPackages := []string{
+		"bad/missing_target",
+		"bad/signature_mismatch",
+		"bad/internal_symbol",
+
+		"good/stable_symbols",
+		"good/cgo_package",
+		"good/pushed_symbols",
+	}
+	analysistest.Run(t, testdata, linkname.Analyzer, testPackages...)
+}
+
+func TestModule(t *testing.T) {
+	// packages of a module whose path does not start with a domain name are still not part of the standard library
+	testdata := filepath.Join(analysistest.TestData(), "mod")
+	setGoroot(t, filepath.Join(analysistest.TestData(), "goroot"))
+	analysistest.Run(t, testdata, linkname.Analyzer, "./...")
+}
+
+func TestInventory(t *testing.T) {
+	// every directive is listed in order, together with the result of resolving its target
+	testdata := analysistest.TestData()
+	setGoroot(t, filepath.Join(testdata, "goroot"))
+	results := analysistest.Run(t, testdata, linkname.Analyzer, "good/stable_symbols")
+	inventory := results[0].Result.(linkname.Inventory)
+
+	expected := []struct {
+		local  string
+		status linkname.Status
+	}{
+		{"nanotime", linkname.Stable},
+		{"memhash", linkname.Stable},
+		{"cheaprand", linkname.Stable},
+		{"semacquire", linkname.Stable},
+		{"framesNext", linkname.Public},
+		{"exported", linkname.Unchecked},
+		{"helper", linkname.Unchecked},
+	}
+	if len(inventory) != len(expected) {
+		t.Fatalf("found %d directives, expected %d", len(inventory), len(expected))
+	}
+	for i, link := range inventory {
+		if link.Local != expected[i].local || link.Status != expected[i].status {
+			t.Errorf("directive %d is %s with status %d, expected %s with status %d", i, link.Local, link.Status,
+				expected[i].local, expected[i].status)
+		}
+	}
+}
+
+func setGoroot(t *testing.T, goroot string) {
+	// resolve the targets against the fixed sources in testdata rather than the GOROOT that runs the tests
+	flag := linkname.Analyzer.Flags.Lookup("goroot")
+	previous := flag.Value.String()
+	if err := flag.Value.Set(goroot); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = flag.Value.Set(previous) })
+}
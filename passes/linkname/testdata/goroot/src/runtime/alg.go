@@ -0,0 +1,8 @@
+package runtime
+
+import "unsafe"
+
+//go:linkname memhash
+func memhash(p unsafe.Pointer, h, s uintptr) uintptr {
+	return h ^ s ^ uintptr(p)
+}
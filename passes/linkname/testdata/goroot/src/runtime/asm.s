@@ -0,0 +1,10 @@
+#include "textflag.h"
+
+TEXT ·publicationBarrier(SB),NOSPLIT,$0-0
+	RET
+
+TEXT runtime·memequal<ABIInternal>(SB),NOSPLIT,$0-25
+	RET
+
+TEXT sync∕atomic·Load(SB),NOSPLIT,$0-12
+	RET
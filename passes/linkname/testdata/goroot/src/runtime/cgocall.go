@@ -0,0 +1,7 @@
+package runtime
+
+import "unsafe"
+
+func cgocall(fn, arg unsafe.Pointer) int32 {
+	return 0
+}
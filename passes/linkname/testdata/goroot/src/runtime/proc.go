@@ -0,0 +1,18 @@
+package runtime
+
+import _ "unsafe"
+
+func gcount(includeSys bool) int32 {
+	return 1
+}
+
+//go:linkname procyield
+func procyield(cycles uint32) {
+	procyieldAsm(cycles)
+}
+
+func procyieldAsm(cycles uint32)
+
+//go:linkname sync_runtime_Semacquire sync.runtime_Semacquire
+func sync_runtime_Semacquire(addr *uint32) {
+}
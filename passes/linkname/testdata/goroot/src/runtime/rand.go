@@ -0,0 +1,8 @@
+package runtime
+
+import _ "unsafe"
+
+//go:linkname cheaprand
+func cheaprand() uint32 {
+	return 4
+}
@@ -0,0 +1,9 @@
+package runtime
+
+type Frames struct{}
+
+type Frame struct{}
+
+func (ci *Frames) Next() (frame Frame, more bool) {
+	return Frame{}, false
+}
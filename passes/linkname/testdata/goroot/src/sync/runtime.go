@@ -0,0 +1,4 @@
+package sync
+
+// provided by the runtime
+func runtime_Semacquire(s *uint32)
@@ -0,0 +1,5 @@
+package time
+
+func quote(s string) string {
+	return "\"" + s + "\""
+}
@@ -0,0 +1,3 @@
+module t1
+
+go 1.22
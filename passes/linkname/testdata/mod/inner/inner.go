@@ -0,0 +1,5 @@
+package inner
+
+func secret() int {
+	return 42
+}
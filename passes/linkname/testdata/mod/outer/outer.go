@@ -0,0 +1,6 @@
+package outer
+
+import _ "unsafe"
+
+//go:linkname secret t1/inner.secret
+func secret() int
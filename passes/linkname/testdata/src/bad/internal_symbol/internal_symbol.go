@@ -0,0 +1,15 @@
+package internal_symbol
+
+import "unsafe"
+
+//go:linkname gcount runtime.gcount // want "go:linkname target runtime.gcount is internal to package runtime and not kept available for go:linkname, so it may change or be blocked by the linker in any Go release"
+func gcount(includeSys bool) int32
+
+//go:linkname quote time.quote // want "go:linkname target time.quote is internal to package time and not kept available for go:linkname"
+func quote(s string) string
+
+//go:linkname publicationBarrier runtime.publicationBarrier // want "go:linkname target runtime.publicationBarrier is internal to package runtime and not kept available for go:linkname"
+func publicationBarrier()
+
+//go:linkname memequal runtime.memequal // want "go:linkname target runtime.memequal is internal to package runtime and not kept available for go:linkname"
+func memequal(a, b unsafe.Pointer, size uintptr) bool
@@ -0,0 +1,12 @@
+package missing_target
+
+import _ "unsafe"
+
+//go:linkname fastrand runtime.fastrand // want "go:linkname target runtime.fastrand does not exist in the standard library of GOROOT"
+func fastrand() uint32
+
+//go:linkname atomicLoad runtime/internal/atomic.Load // want "go:linkname target runtime/internal/atomic.Load does not exist, because there is no package runtime/internal/atomic in the standard library of GOROOT"
+func atomicLoad(ptr *uint32) uint32
+
+//go:linkname frameName runtime.(*Frame).Name // want "go:linkname target runtime.\\(\\*Frame\\).Name does not exist in the standard library of GOROOT"
+func frameName(frame uintptr) string
@@ -0,0 +1,19 @@
+package signature_mismatch
+
+import "unsafe"
+
+//go:linkname procyield runtime.procyield // want "go:linkname target runtime.procyield is declared as func\\(cycles uint32\\) in GOROOT, but procyield is declared as func\\(cycles int\\)"
+func procyield(cycles int)
+
+//go:linkname nanotime runtime.nanotime // want "go:linkname target runtime.nanotime is declared as func\\(\\) int64 in GOROOT, but nanotime is declared as func\\(\\) \\(int64, error\\)"
+func nanotime() (int64, error)
+
+//go:linkname memhash runtime.memhash // want "go:linkname target runtime.memhash is declared as func\\(p unsafe.Pointer, h, s uintptr\\) uintptr in GOROOT, but memhash is declared as func\\(p uintptr, seed uintptr, length uintptr\\) uintptr"
+func memhash(p uintptr, seed, length uintptr) uintptr
+
+//go:linkname cheaprand runtime.cheaprand // want "go:linkname target runtime.cheaprand is a function, but cheaprand is not"
+var cheaprand func() uint32
+
+func Hash(p unsafe.Pointer) uintptr {
+	return memhash(uintptr(p), 0, 8)
+}
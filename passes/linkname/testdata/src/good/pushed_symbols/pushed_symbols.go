@@ -0,0 +1,23 @@
+package pushed_symbols
+
+import (
+	"time"
+	_ "unsafe"
+)
+
+//go:linkname unusedIfaceIndir reflect.ifaceIndir
+func unusedIfaceIndir() bool {
+	return false
+}
+
+//go:linkname legacyTimeTimeAbs time.Time.abs
+func legacyTimeTimeAbs(t time.Time) uint64 {
+	return 0
+}
+
+//go:linkname alias good/pushed_symbols.original
+func alias() int
+
+func original() int {
+	return 0
+}
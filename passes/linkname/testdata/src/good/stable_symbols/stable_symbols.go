@@ -0,0 +1,27 @@
+package stable_symbols
+
+import (
+	"runtime"
+	"unsafe"
+)
+
+//go:linkname nanotime runtime.nanotime
+func nanotime() int64
+
+//go:linkname memhash runtime.memhash
+func memhash(p unsafe.Pointer, seed, length uintptr) uintptr
+
+//go:linkname cheaprand runtime.cheaprand
+func cheaprand() uint32
+
+//go:linkname semacquire sync.runtime_Semacquire
+func semacquire(s *uint32)
+
+//go:linkname framesNext runtime.(*Frames).Next
+func framesNext(frames *runtime.Frames) (runtime.Frame, bool)
+
+//go:linkname exported
+func exported() {}
+
+//go:linkname helper example.com/other.helper
+func helper()
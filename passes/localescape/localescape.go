@@ -0,0 +1,246 @@
+package localescape
+
+import (
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+)
+
+// Analyzer is a golang.org/x/tools/go/analysis style linter pass.
+// Use this with the Vet-style infrastructure.
+var Analyzer = &analysis.Analyzer{
+	Name:     "localescape",
+	Doc:      "reports slices backed by function-local variables through a reflect header or unsafe.Slice that escape the function",
+	Run:      run,
+	Requires: []*analysis.Analyzer{buildssa.Analyzer},
+}
+
+// backedSlice is a slice whose data was set to the address of a function-local variable
+type backedSlice struct {
+	// local is the variable that backs the slice
+	local *ssa.Alloc
+	// via describes how the slice was derived from the variable
+	via string
+	// pos is the position where the slice was derived from the variable
+	pos token.Pos
+	// values are the slice values that share the data of the variable
+	values []ssa.Value
+}
+
+// syntheticAllocs are the comments of SSA allocations that do not belong to a variable
+var syntheticAllocs = map[string]bool{"complit": true, "new": true, "slicelit": true, "varargs": true}
+
+/**
+ * run is the entry point to the analysis pass
+ */
+func run(pass *analysis.Pass) (interface{}, error) {
+	// get results from required SSA analyzer
+	ssaResult := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+
+	for _, function := range ssaResult.SrcFuncs {
+		// find the slices backed by local variables, and report every way they escape the function
+		for _, slice := range backedSlices(function) {
+			for _, path := range escapePaths(slice.values, function) {
+				pass.Reportf(slice.pos, "slice backed by local variable %s escapes the function: %s -> %s -> %s",
+					slice.local.Comment, slice.local.Comment, slice.via, path)
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+/**
+ * finds the slices in a function whose data was set to the address of a function-local variable, either through the
+ * Data field of a reflect header or as the base of unsafe.Slice
+ */
+func backedSlices(function *ssa.Function) []backedSlice {
+	var slices []backedSlice
+	for _, block := range function.Blocks {
+		for _, instr := range block.Instrs {
+			switch instr := instr.(type) {
+			case *ssa.Store:
+				// an assignment to the Data field of a reflect header
+				fieldAddr, ok := instr.Addr.(*ssa.FieldAddr)
+				if !ok || !resolve.IsHeaderDataField(fieldAddr) {
+					continue
+				}
+				local := localVariable(instr.Val)
+				if local == nil {
+					continue
+				}
+				header := memoryRoot(fieldAddr.X)
+				if header == nil {
+					continue
+				}
+				pos := instr.Pos()
+				if pos == token.NoPos {
+					pos = fieldAddr.Pos()
+				}
+				slices = append(slices, backedSlice{
+					local:  local,
+					via:    "header Data",
+					pos:    pos,
+					values: sliceLoads(header, function),
+				})
+			case *ssa.Call:
+				// a call to unsafe.Slice
+				builtin, ok := instr.Call.Value.(*ssa.Builtin)
+				if !ok || builtin.Name() != "Slice" || len(instr.Call.Args) != 2 {
+					continue
+				}
+				local := localVariable(instr.Call.Args[0])
+				if local == nil {
+					continue
+				}
+				slices = append(slices, backedSlice{
+					local:  local,
+					via:    "unsafe.Slice",
+					pos:    instr.Pos(),
+					values: []ssa.Value{instr},
+				})
+			}
+		}
+	}
+	return slices
+}
+
+/**
+ * follows a pointer or uintptr value back to the address of a function-local array or struct variable, or an element
+ * or field of it. Returns nil if the value is derived from something else
+ */
+func localVariable(value ssa.Value) *ssa.Alloc {
+	switch v := value.(type) {
+	case *ssa.Alloc:
+		// only variables are followed, not the values created by new or composite literals
+		if !token.IsIdentifier(v.Comment) || syntheticAllocs[v.Comment] {
+			return nil
+		}
+		switch v.Type().Underlying().(*types.Pointer).Elem().Underlying().(type) {
+		case *types.Array, *types.Struct:
+			return v
+		}
+	case *ssa.Convert:
+		return localVariable(v.X)
+	case *ssa.ChangeType:
+		return localVariable(v.X)
+	case *ssa.FieldAddr:
+		return localVariable(v.X)
+	case *ssa.IndexAddr:
+		// elements of slices are not part of the variable
+		if _, ok := v.X.Type().Underlying().(*types.Pointer); ok {
+			return localVariable(v.X)
+		}
+	case *ssa.BinOp:
+		// pointer arithmetic keeps the base pointer
+		if v.Op == token.ADD || v.Op == token.SUB {
+			if local := localVariable(v.X); local != nil {
+				return local
+			}
+			return localVariable(v.Y)
+		}
+	}
+	return nil
+}
+
+/**
+ * follows a pointer to a reflect header back to the memory it points to, which is either a header variable or a slice
+ * or string variable that was cast to a header
+ */
+func memoryRoot(value ssa.Value) *ssa.Alloc {
+	switch v := value.(type) {
+	case *ssa.Alloc:
+		return v
+	case *ssa.Convert:
+		return memoryRoot(v.X)
+	case *ssa.ChangeType:
+		return memoryRoot(v.X)
+	}
+	return nil
+}
+
+/**
+ * finds the values of a function that are loaded as a slice or string from the memory of a header
+ */
+func sliceLoads(root *ssa.Alloc, function *ssa.Function) []ssa.Value {
+	var values []ssa.Value
+	for _, block := range function.Blocks {
+		for _, instr := range block.Instrs {
+			load, ok := instr.(*ssa.UnOp)
+			if !ok || load.Op != token.MUL || memoryRoot(load.X) != root {
+				continue
+			}
+			switch t := load.Type().Underlying().(type) {
+			case *types.Slice:
+				values = append(values, load)
+			case *types.Basic:
+				if t.Info()&types.IsString != 0 {
+					values = append(values, load)
+				}
+			}
+		}
+	}
+	return values
+}
+
+/**
+ * follows slice values through the function and describes every way they escape it: returned, stored in a global
+ * variable, or sent on a channel
+ */
+func escapePaths(values []ssa.Value, function *ssa.Function) []string {
+	var paths []string
+	visited := make(map[ssa.Value]bool)
+
+	var follow func(value ssa.Value, steps []string)
+	follow = func(value ssa.Value, steps []string) {
+		if visited[value] {
+			return
+		}
+		visited[value] = true
+
+		referrers := value.Referrers()
+		if referrers == nil {
+			return
+		}
+		for _, referrer := range *referrers {
+			switch r := referrer.(type) {
+			case *ssa.Return:
+				paths = append(paths, path(steps, "returned from "+function.Name()))
+			case *ssa.Store:
+				if global, ok := r.Addr.(*ssa.Global); ok && r.Val == value {
+					paths = append(paths, path(steps, "stored in global variable "+global.Name()))
+				}
+			case *ssa.Send:
+				if r.X == value {
+					paths = append(paths, path(steps, "sent on a channel"))
+				}
+			case *ssa.Slice:
+				follow(r, append(steps, "resliced"))
+			case *ssa.Phi:
+				follow(r, steps)
+			case *ssa.ChangeType:
+				follow(r, steps)
+			case *ssa.MakeInterface:
+				follow(r, steps)
+			}
+		}
+	}
+
+	for _, value := range values {
+		follow(value, nil)
+	}
+	return paths
+}
+
+/**
+ * joins the steps of an escape path
+ */
+func path(steps []string, sink string) string {
+	return strings.Join(append(append([]string{}, steps...), sink), " -> ")
+}
@@ -0,0 +1,19 @@
+package localescape_test
+
+import (
+	"github.com/jlauinger/go-safer/passes/localescape"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"testing"
+)
+
+func Test(t *testing.T) {
+	// use go vet infrastructure testing and supply annotated code examples
+	testdata := analysistest.TestData()
+	testPackages := []string{
+		"bad/header_data",
+		"bad/unsafe_slice",
+
+		"good/local_use",
+	}
+	analysistest.Run(t, testdata, localescape.Analyzer, testPackages...)
+}
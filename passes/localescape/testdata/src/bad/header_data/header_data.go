@@ -0,0 +1,62 @@
+package header_data
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+var cache []byte
+
+func ReturnedSlice() (b []byte) {
+	var buf [16]byte
+	sH := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	sH.Data = uintptr(unsafe.Pointer(&buf)) // want "slice backed by local variable buf escapes the function: buf -> header Data -> returned from ReturnedSlice"
+	sH.Len = len(buf)
+	sH.Cap = len(buf)
+	return
+}
+
+func GlobalSlice() {
+	var buf [16]byte
+	var b []byte
+	sH := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	sH.Data = uintptr(unsafe.Pointer(&buf[4])) // want "slice backed by local variable buf escapes the function: buf -> header Data -> resliced -> stored in global variable cache"
+	sH.Len = 8
+	sH.Cap = 8
+	cache = b[:4]
+}
+
+type record struct {
+	id   int64
+	name [8]byte
+}
+
+func SentString(out chan string) {
+	var r record
+	var s string
+	sH := (*reflect.StringHeader)(unsafe.Pointer(&s))
+	sH.Data = uintptr(unsafe.Pointer(&r.name)) // want "slice backed by local variable r escapes the function: r -> header Data -> sent on a channel"
+	sH.Len = len(r.name)
+	out <- s
+}
+
+func HeaderLiteral() []byte {
+	var buf [16]byte
+	h := reflect.SliceHeader{
+		Data: uintptr(unsafe.Pointer(&buf)), // want "slice backed by local variable buf escapes the function: buf -> header Data -> returned from HeaderLiteral"
+		Len:  len(buf),
+		Cap:  len(buf),
+	}
+	return *(*[]byte)(unsafe.Pointer(&h))
+}
+
+type MysteryType reflect.SliceHeader
+
+func LookAlikeHeader() (b []byte) {
+	var buf [16]byte
+	sH := (*MysteryType)(unsafe.Pointer(&b))
+	sH.Data = uintptr(unsafe.Pointer(&buf)) // want "slice backed by local variable buf escapes the function: buf -> header Data -> returned from LookAlikeHeader"
+	sH.Len = len(buf)
+	sH.Cap = len(buf)
+	return
+}
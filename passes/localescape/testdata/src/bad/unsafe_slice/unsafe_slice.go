@@ -0,0 +1,21 @@
+package unsafe_slice
+
+import (
+	"unsafe"
+)
+
+var cache []uint32
+
+func ReturnedSlice() []byte {
+	var buf [16]byte
+	return unsafe.Slice(&buf[0], len(buf)) // want "slice backed by local variable buf escapes the function: buf -> unsafe.Slice -> returned from ReturnedSlice"
+}
+
+func GlobalSlice(n int) {
+	var values [4]uint32
+	s := unsafe.Slice((*uint32)(unsafe.Pointer(&values)), n) // want "slice backed by local variable values escapes the function: values -> unsafe.Slice -> stored in global variable cache"
+	if n > 2 {
+		s = s[1:]
+	}
+	cache = s
+}
@@ -0,0 +1,34 @@
+package local_use
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+func LocalSum() (sum int) {
+	var buf [16]byte
+	var b []byte
+	sH := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	sH.Data = uintptr(unsafe.Pointer(&buf))
+	sH.Len = len(buf)
+	sH.Cap = len(buf)
+	for _, x := range b {
+		sum += int(x)
+	}
+	return
+}
+
+func CopiedSlice() []byte {
+	var buf [16]byte
+	s := unsafe.Slice(&buf[0], len(buf))
+	return append([]byte(nil), s...)
+}
+
+func HeapArray() []byte {
+	buf := new([16]byte)
+	return unsafe.Slice(&buf[0], len(buf))
+}
+
+func ParameterSlice(values []uint32) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(&values[0])), 4*len(values))
+}
@@ -0,0 +1,248 @@
+package runtimelayout
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+	"github.com/jlauinger/go-safer/passes/internal/stdsource"
+)
+
+// Analyzer is a golang.org/x/tools/go/analysis style linter pass.
+// Use this with the Vet-style infrastructure.
+var Analyzer = &analysis.Analyzer{
+	Name:             "runtimelayout",
+	Doc:              "reports unsafe code that depends on the internal layout of runtime types or on runtime symbols",
+	Run:              run,
+	Requires:         []*analysis.Analyzer{inspect.Analyzer},
+	RunDespiteErrors: true,
+}
+
+// goroot is the GOROOT whose standard library source tells which symbols are kept available for go:linkname. It
+// defaults to the GOROOT that go-safer runs with
+var goroot string
+
+func init() {
+	Analyzer.Flags.StringVar(&goroot, "goroot", "",
+		"GOROOT to look up go:linkname targets in, instead of the one go-safer runs with")
+}
+
+// mirroredType is a runtime-internal type that is commonly copied into other packages to peek into the runtime,
+// together with the names of characteristic fields that a copy is expected to share
+type mirroredType struct {
+	name   string
+	fields []string
+}
+
+// mirroredTypes maps the names of runtime-internal types to the type they mirror
+var mirroredTypes = map[string]mirroredType{
+	"eface":    {"runtime.eface", []string{"_type", "typ", "rtype", "data", "word"}},
+	"iface":    {"runtime.iface", []string{"tab", "itab", "data", "word"}},
+	"itab":     {"runtime.itab", []string{"inter", "_type", "typ", "hash", "fun"}},
+	"_type":    {"runtime._type", []string{"size", "ptrdata", "hash", "tflag", "align", "kind", "equal", "str"}},
+	"rtype":    {"reflect.rtype", []string{"size", "ptrdata", "hash", "tflag", "align", "kind", "equal", "str"}},
+	"hmap":     {"runtime.hmap", []string{"count", "flags", "B", "noverflow", "hash0", "buckets", "oldbuckets"}},
+	"bmap":     {"runtime.bmap", []string{"tophash", "keys", "elems", "overflow"}},
+	"hiter":    {"runtime.hiter", []string{"key", "elem", "t", "h", "buckets", "bptr", "startBucket", "offset"}},
+	"mapextra": {"runtime.mapextra", []string{"overflow", "oldoverflow", "nextOverflow"}},
+	"hchan": {"runtime.hchan", []string{"qcount", "dataqsiz", "buf", "elemsize", "closed", "sendx", "recvx",
+		"recvq", "sendq"}},
+	"waitq": {"runtime.waitq", []string{"first", "last"}},
+	"sudog": {"runtime.sudog", []string{"g", "next", "prev", "elem", "isSelect", "c"}},
+	"g": {"runtime.g", []string{"stack", "stackguard0", "stackguard1", "_panic", "_defer", "m", "sched", "goid",
+		"atomicstatus"}},
+	"m":            {"runtime.m", []string{"g0", "curg", "procid", "p", "mallocing", "locks", "gsignal", "tls"}},
+	"p":            {"runtime.p", []string{"id", "status", "link", "schedtick", "syscalltick", "m", "mcache", "runq"}},
+	"funcval":      {"runtime.funcval", []string{"fn"}},
+	"moduledata":   {"runtime.moduledata", []string{"pclntable", "ftab", "filetab", "text", "etext", "typelinks"}},
+	"stringStruct": {"runtime.stringStruct", []string{"str", "len"}},
+}
+
+const unstable = "which is internal to the runtime and may change in any Go release"
+
+/**
+ * run is the entry point to the analysis pass
+ */
+func run(pass *analysis.Pass) (interface{}, error) {
+	// get results from required inspect analyzer
+	inspectResult := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	// go:linkname directives are comments, so they are not visited by the inspector
+	for _, file := range pass.Files {
+		checkLinknames(file, pass)
+	}
+
+	inspectResult.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		node := n.(*ast.CallExpr)
+
+		// find casts of the form (*T)(unsafe.Pointer(...))
+		target, ok := pass.TypesInfo.Types[node.Fun]
+		if !ok || !target.IsType() || len(node.Args) != 1 {
+			return
+		}
+		pointer, ok := target.Type.Underlying().(*types.Pointer)
+		if !ok || !resolve.IsUnsafePointerType(pass.TypesInfo.TypeOf(node.Args[0])) {
+			return
+		}
+		qualifier := types.RelativeTo(pass.Pkg)
+		targetName := types.TypeString(pointer.Elem(), qualifier)
+
+		// the source value might be one whose representation is defined by the runtime
+		if source := pointedToType(node.Args[0], pass); source != nil && !types.Identical(source, pointer.Elem()) {
+			if assumed := runtimeRepresentation(source); assumed != "" {
+				// reading the word of a map or channel variable itself only assumes that it is a pointer, the runtime
+				// type is only assumed once that pointer is dereferenced
+				if kind := referenceKind(source); kind != "" && isPointerWord(pointer.Elem()) {
+					pass.Reportf(node.Pos(), "reinterpreting %s as %s assumes that %s values are a single pointer, "+
+						"which the language does not guarantee", types.TypeString(source, qualifier), targetName, kind)
+					return
+				}
+				pass.Reportf(node.Pos(), "reinterpreting %s as %s assumes the layout of %s, %s",
+					types.TypeString(source, qualifier), targetName, assumed, unstable)
+				return
+			}
+		}
+
+		// otherwise, the target might be a copy of a runtime type
+		if mirrored, ok := mirrorOf(pointer.Elem()); ok {
+			pass.Reportf(node.Pos(), "casting to %s assumes that it mirrors the layout of %s, %s", targetName,
+				mirrored.name, unstable)
+		}
+	})
+
+	return nil, nil
+}
+
+/**
+ * reports go:linkname directives that refer to a symbol in the runtime, reflect, or an internal package, unless the
+ * standard library keeps the symbol available for go:linkname
+ */
+func checkLinknames(file *ast.File, pass *analysis.Pass) {
+	// cgo links its own helpers into the runtime
+	if resolve.IsCgoGenerated(file) {
+		return
+	}
+	for _, linkname := range resolve.Linknames(file) {
+		// without a target, or with a body, the directive only exports a local symbol
+		if linkname.Target == "" || resolve.IsPushedLinkname(linkname, pass.Files, pass.Pkg) {
+			continue
+		}
+		path, _ := resolve.SplitSymbol(linkname.Target)
+		if !stdsource.IsRuntimeInternal(goroot, path) || stdsource.IsPushed(goroot, linkname.Target) {
+			continue
+		}
+		pass.Reportf(linkname.Comment.Pos(), "go:linkname ties %s to the symbol %s, whose signature and behavior are "+
+			"internal to the runtime and may change in any Go release", linkname.Local, linkname.Target)
+	}
+}
+
+/**
+ * checks whether a type is a copy of a runtime type. Besides the name, the struct must share characteristic fields
+ * with the runtime type: two of them, or its only field
+ */
+func mirrorOf(t types.Type) (mirroredType, bool) {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return mirroredType{}, false
+	}
+	s, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return mirroredType{}, false
+	}
+	mirrored, ok := mirroredTypes[named.Obj().Name()]
+	if !ok {
+		return mirroredType{}, false
+	}
+
+	shared := 0
+	for i := 0; i < s.NumFields(); i++ {
+		for _, name := range mirrored.fields {
+			if s.Field(i).Name() == name {
+				shared++
+				break
+			}
+		}
+	}
+	return mirrored, shared > 0 && shared >= min(2, s.NumFields())
+}
+
+/**
+ * returns the type of the value that an unsafe.Pointer expression points to, like T for unsafe.Pointer(&t) or
+ * unsafe.Pointer(p) with p of type *T. It returns nil if the type is not known
+ */
+func pointedToType(expr ast.Expr, pass *analysis.Pass) types.Type {
+	expr = ast.Unparen(expr)
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || !resolve.IsUnsafePointerConversion(pass.TypesInfo, call) {
+		return nil
+	}
+	t := pass.TypesInfo.TypeOf(call.Args[0])
+	if t == nil {
+		return nil
+	}
+	pointer, ok := t.Underlying().(*types.Pointer)
+	if !ok {
+		return nil
+	}
+	return pointer.Elem()
+}
+
+/**
+ * returns chan or map for channel and map types, whose values are a pointer to the runtime-internal type, or an empty
+ * string for other types
+ */
+func referenceKind(t types.Type) string {
+	switch t.Underlying().(type) {
+	case *types.Chan:
+		return "chan"
+	case *types.Map:
+		return "map"
+	}
+	return ""
+}
+
+/**
+ * checks whether a type is a single pointer-sized word that does not refer to a copy of a runtime type, like
+ * unsafe.Pointer or uintptr
+ */
+func isPointerWord(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return u.Kind() == types.UnsafePointer || u.Kind() == types.Uintptr
+	case *types.Pointer:
+		_, mirrored := mirrorOf(u.Elem())
+		return !mirrored
+	}
+	return false
+}
+
+/**
+ * describes the runtime-internal type that represents values of a type, or returns an empty string if the
+ * representation is defined by the language
+ */
+func runtimeRepresentation(t types.Type) string {
+	if named, ok := types.Unalias(t).(*types.Named); ok && named.Obj().Pkg() != nil &&
+		named.Obj().Pkg().Path() == "reflect" && named.Obj().Name() == "Value" {
+		return "the unexported fields of reflect.Value"
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Interface:
+		// type parameters have interface constraints, but their values are not interfaces
+		if _, ok := t.(*types.TypeParam); ok {
+			return ""
+		}
+		if u.Empty() {
+			return "runtime.eface"
+		}
+		return "runtime.iface"
+	case *types.Map:
+		return "runtime.hmap (internal/runtime/maps.Map since Go 1.24)"
+	case *types.Chan:
+		return "runtime.hchan"
+	}
+	return ""
+}
@@ -0,0 +1,35 @@
+package runtimelayout_test
+
+import (
+	"github.com/jlauinger/go-safer/passes/runtimelayout"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"path/filepath"
+	"testing"
+)
+
+func Test(t *testing.T) {
+	// use go vet infrastructure testing and supply annotated code examples
+	testdata := analysistest.TestData()
+	setGoroot(t, filepath.Join(testdata, "goroot"))
+	testPackages := []string{
+		"bad/interface_internals",
+		"bad/container_internals",
+		"bad/runtime_linkname",
+		"bad/scheduler_internals",
+
+		"good/public_api",
+		"good/cgo_package",
+	}
+	analysistest.Run(t, testdata, runtimelayout.Analyzer, testPackages...)
+}
+
+func setGoroot(t *testing.T, goroot string) {
+	// look up the symbols that are kept available in the fixed sources in testdata rather than the GOROOT that runs
+	// the tests
+	flag := runtimelayout.Analyzer.Flags.Lookup("goroot")
+	previous := flag.Value.String()
+	if err := flag.Value.Set(goroot); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = flag.Value.Set(previous) })
+}
@@ -0,0 +1,35 @@
+package container_internals
+
+import "unsafe"
+
+type hmap struct {
+	count int
+	flags uint8
+	B     uint8
+}
+
+type hchan struct {
+	qcount   uint
+	dataqsiz uint
+}
+
+func MapCount(m map[string]int) int {
+	header := *(*unsafe.Pointer)(unsafe.Pointer(&m)) // want "reinterpreting map\\[string\\]int as unsafe.Pointer assumes that map values are a single pointer, which the language does not guarantee"
+	return (*hmap)(header).count                     // want "casting to hmap assumes that it mirrors the layout of runtime.hmap, which is internal to the runtime"
+}
+
+func Empty(m map[int]int) bool {
+	return (**hmap)(unsafe.Pointer(&m)) == nil // want "reinterpreting map\\[int\\]int as \\*hmap assumes the layout of runtime.hmap"
+}
+
+func Queued(c chan int) uint {
+	return (*(**hchan)(unsafe.Pointer(&c))).qcount // want "reinterpreting chan int as \\*hchan assumes the layout of runtime.hchan"
+}
+
+func ChannelWord(c chan int) uintptr {
+	return *(*uintptr)(unsafe.Pointer(&c)) // want "reinterpreting chan int as uintptr assumes that chan values are a single pointer"
+}
+
+func Buffer(c chan int) uint {
+	return (*[2]uint)(unsafe.Pointer(&c))[1] // want "reinterpreting chan int as \\[2\\]uint assumes the layout of runtime.hchan"
+}
@@ -0,0 +1,40 @@
+package interface_internals
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+type eface struct {
+	typ  unsafe.Pointer
+	data unsafe.Pointer
+}
+
+type flag uintptr
+
+type value struct {
+	typ  unsafe.Pointer
+	ptr  unsafe.Pointer
+	flag flag
+}
+
+func DataPointer(i interface{}) unsafe.Pointer {
+	return (*[2]unsafe.Pointer)(unsafe.Pointer(&i))[1] // want "reinterpreting interface{} as \\[2\\]unsafe.Pointer assumes the layout of runtime.eface, which is internal to the runtime and may change in any Go release"
+}
+
+func TypePointer(i any) unsafe.Pointer {
+	return (*eface)(unsafe.Pointer(&i)).typ // want "reinterpreting any as eface assumes the layout of runtime.eface"
+}
+
+func ErrorData(err error) unsafe.Pointer {
+	words := (*[2]unsafe.Pointer)(unsafe.Pointer(&err)) // want "reinterpreting error as \\[2\\]unsafe.Pointer assumes the layout of runtime.iface"
+	return words[1]
+}
+
+func ValuePointer(v reflect.Value) unsafe.Pointer {
+	return (*value)(unsafe.Pointer(&v)).ptr // want "reinterpreting reflect.Value as value assumes the layout of the unexported fields of reflect.Value"
+}
+
+func MirroredData(p unsafe.Pointer) unsafe.Pointer {
+	return (*eface)(p).data // want "casting to eface assumes that it mirrors the layout of runtime.eface"
+}
@@ -0,0 +1,19 @@
+package runtime_linkname
+
+import "unsafe"
+
+//go:linkname gcount runtime.gcount // want "go:linkname ties gcount to the symbol runtime.gcount, whose signature and behavior are internal to the runtime and may change in any Go release"
+func gcount() int32
+
+//go:linkname mapaccess runtime.mapaccess1_faststr // want "go:linkname ties mapaccess to the symbol runtime.mapaccess1_faststr"
+func mapaccess(t unsafe.Pointer, h unsafe.Pointer, key string) unsafe.Pointer
+
+//go:linkname typelinks reflect.typelinks // want "go:linkname ties typelinks to the symbol reflect.typelinks"
+func typelinks() ([]unsafe.Pointer, [][]int32)
+
+//go:linkname procPin internal/runtime/sys.procPin // want "go:linkname ties procPin to the symbol internal/runtime/sys.procPin"
+func procPin() int
+
+func Goroutines() int32 {
+	return gcount()
+}
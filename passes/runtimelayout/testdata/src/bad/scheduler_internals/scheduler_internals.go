@@ -0,0 +1,27 @@
+package scheduler_internals
+
+import "unsafe"
+
+type stack struct {
+	lo uintptr
+	hi uintptr
+}
+
+type g struct {
+	stack       stack
+	stackguard0 uintptr
+	stackguard1 uintptr
+}
+
+type funcval struct {
+	fn uintptr
+}
+
+func StackBounds(gp unsafe.Pointer) (uintptr, uintptr) {
+	s := (*g)(gp).stack // want "casting to g assumes that it mirrors the layout of runtime.g, which is internal to the runtime"
+	return s.lo, s.hi
+}
+
+func Entry(f unsafe.Pointer) uintptr {
+	return (*funcval)(f).fn // want "casting to funcval assumes that it mirrors the layout of runtime.funcval"
+}
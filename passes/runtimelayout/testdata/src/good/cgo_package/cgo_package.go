@@ -0,0 +1,8 @@
+package cgo_package
+
+// static int answer(void) { return 42; }
+import "C"
+
+func Answer() int {
+	return int(C.answer())
+}
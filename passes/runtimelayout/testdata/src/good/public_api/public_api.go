@@ -0,0 +1,75 @@
+package public_api
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+type header struct {
+	count int
+}
+
+//go:linkname exported
+func exported() {}
+
+//go:linkname helper example.com/other.helper
+func helper()
+
+// the runtime keeps nanotime available for go:linkname
+//
+//go:linkname nanotime runtime.nanotime
+func nanotime() int64
+
+func DataPointer(v interface{}) unsafe.Pointer {
+	// reflect exposes the data pointer of values that have one
+	return reflect.ValueOf(v).UnsafePointer()
+}
+
+func Count(m map[string]int) int {
+	// the length of a map is available without looking at its header
+	return len(m)
+}
+
+func Reinterpret(p *header) *[1]int {
+	// casts between types of the package do not depend on the runtime
+	return (*[1]int)(unsafe.Pointer(p))
+}
+
+func Identity(i *interface{}) *interface{} {
+	// casting to the same type keeps the representation opaque
+	return (*interface{})(unsafe.Pointer(i))
+}
+
+func Generic[T any](value *T) *[8]byte {
+	// type parameters are not interfaces at run time
+	return (*[8]byte)(unsafe.Pointer(value))
+}
+
+type vec struct {
+	x, y float64
+}
+
+type p struct {
+	x, y float64
+}
+
+type m struct {
+	id   int
+	name string
+}
+
+func Point(v *vec) *p {
+	// types that are named like runtime types, but share none of their fields, are not copies of them
+	return (*p)(unsafe.Pointer(v))
+}
+
+func Model(value unsafe.Pointer) *m {
+	return (*m)(value)
+}
+
+// a function with a body provides the symbol instead of depending on it
+//
+//go:linkname gcount runtime.gcount
+func gcount() int32 {
+	return 0
+}
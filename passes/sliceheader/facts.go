@@ -0,0 +1,242 @@
+package sliceheader
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+)
+
+// writesHeaderParams is exported for functions that assign to fields of reflect headers passed as pointer parameters,
+// either directly or by passing them on to other functions that do. Params holds the parameter indices
+type writesHeaderParams struct {
+	Params []int
+}
+
+func (*writesHeaderParams) AFact() {}
+
+func (f *writesHeaderParams) String() string {
+	return fmt.Sprintf("writesHeaderParams%v", f.Params)
+}
+
+// returnsSafeHeader is exported for functions with a single reflect header result that is always derived by casting a
+// real slice or string
+type returnsSafeHeader struct{}
+
+func (*returnsSafeHeader) AFact() {}
+
+func (*returnsSafeHeader) String() string {
+	return "returnsSafeHeader"
+}
+
+/**
+ * computes and exports the facts about the declared functions in the package under analysis. Because the facts of a
+ * function depend on the facts of the functions it calls, this iterates until the facts do not change anymore
+ */
+func exportHeaderFacts(inspectResult *inspector.Inspector, headers *headerDefinitions, pass *analysis.Pass) {
+	nodeFilter := []ast.Node{
+		(*ast.FuncDecl)(nil), (*ast.AssignStmt)(nil), (*ast.IncDecStmt)(nil), (*ast.CallExpr)(nil), (*ast.ReturnStmt)(nil),
+	}
+
+	for changed := true; changed; {
+		writes := make(map[*types.Func]map[int]bool)
+		returnsSafe := make(map[*types.Func]bool)
+
+		inspectResult.WithStack(nodeFilter, func(n ast.Node, push bool, stack []ast.Node) bool {
+			if !push {
+				return true
+			}
+
+			// find the declared function that the node belongs to, possibly through closures
+			var decl *ast.FuncDecl
+			for _, s := range stack {
+				if d, ok := s.(*ast.FuncDecl); ok {
+					decl = d
+				}
+			}
+			if decl == nil {
+				return true
+			}
+			function, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+			if !ok {
+				return true
+			}
+			if writes[function] == nil {
+				writes[function] = make(map[int]bool)
+			}
+
+			switch node := n.(type) {
+			case *ast.FuncDecl:
+				// functions with a single header result return safe headers, unless a return statement says otherwise
+				if returnsSingleHeader(function) {
+					returnsSafe[function] = true
+				}
+			case *ast.AssignStmt, *ast.IncDecStmt:
+				// assignments to header fields write to the parameters that may be the value of the header
+				for _, lhs := range fieldWriteTargets(node) {
+					object := selectedHeaderObject(lhs, pass)
+					if object == nil {
+						continue
+					}
+					for _, param := range headers.reachingParameters(object, node, stack) {
+						writes[function][param] = true
+					}
+				}
+			case *ast.CallExpr:
+				// passing a header parameter to a function that writes to it is a write, too
+				for _, arg := range headerArgumentsWritten(node, pass) {
+					ident, ok := ast.Unparen(arg).(*ast.Ident)
+					if !ok {
+						continue
+					}
+					object := pass.TypesInfo.ObjectOf(ident)
+					if object == nil || !resolve.IsReflectHeader(object.Type()) {
+						continue
+					}
+					for _, param := range headers.reachingParameters(object, node, stack) {
+						writes[function][param] = true
+					}
+				}
+			case *ast.ReturnStmt:
+				// only return statements of the declared function itself, not of closures within it
+				if returnsSafe[function] && stack[innermostFunction(stack)] == decl &&
+					!returnDerivedByCast(node, decl, headers, stack) {
+					returnsSafe[function] = false
+				}
+			}
+			return true
+		})
+
+		// export the facts that changed compared to the previous iteration
+		changed = false
+		for function, params := range writes {
+			fact := &writesHeaderParams{}
+			for param := range params {
+				fact.Params = append(fact.Params, param)
+			}
+			if len(fact.Params) == 0 {
+				continue
+			}
+			sort.Ints(fact.Params)
+			previous := new(writesHeaderParams)
+			if !pass.ImportObjectFact(function, previous) || len(previous.Params) != len(fact.Params) {
+				pass.ExportObjectFact(function, fact)
+				changed = true
+			}
+		}
+		for function, safe := range returnsSafe {
+			if safe && !pass.ImportObjectFact(function, new(returnsSafeHeader)) {
+				pass.ExportObjectFact(function, new(returnsSafeHeader))
+				changed = true
+			}
+		}
+	}
+}
+
+/**
+ * checks whether a function has exactly one result, which is a reflect header
+ */
+func returnsSingleHeader(function *types.Func) bool {
+	results := function.Type().(*types.Signature).Results()
+	return results.Len() == 1 && resolve.IsReflectHeader(results.At(0).Type())
+}
+
+/**
+ * checks whether the header returned by a return statement is derived by casting a real slice or string
+ */
+func returnDerivedByCast(returnStmt *ast.ReturnStmt, decl *ast.FuncDecl, headers *headerDefinitions,
+	stack []ast.Node) bool {
+	// a bare return returns the named result
+	var result ast.Expr
+	if len(returnStmt.Results) == 0 {
+		results := decl.Type.Results
+		if len(results.List) != 1 || len(results.List[0].Names) != 1 {
+			return false
+		}
+		result = results.List[0].Names[0]
+	} else {
+		result = ast.Unparen(returnStmt.Results[0])
+	}
+
+	// for header variables, all definitions reaching the return must be safe casts
+	if ident, ok := result.(*ast.Ident); ok {
+		object := headers.pass.TypesInfo.ObjectOf(ident)
+		if object == nil || !resolve.IsReflectHeader(object.Type()) {
+			return false
+		}
+		definitions, ok := headers.reaching(object, returnStmt, stack)
+		if !ok || len(definitions) == 0 {
+			return false
+		}
+		for _, d := range definitions {
+			if !headers.safe(d) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// otherwise, the returned expression itself must be safe
+	return headers.valueSafe(result)
+}
+
+/**
+ * returns the header object whose field is selected by an assignment target, if it is a header variable
+ */
+func selectedHeaderObject(lhs ast.Expr, pass *analysis.Pass) types.Object {
+	selector, ok := lhs.(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	ident, ok := selector.X.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	object := pass.TypesInfo.ObjectOf(ident)
+	if object == nil || !resolve.IsReflectHeader(object.Type()) {
+		return nil
+	}
+	return object
+}
+
+/**
+ * returns the arguments of a call that are passed to header parameters which the callee writes to
+ */
+func headerArgumentsWritten(call *ast.CallExpr, pass *analysis.Pass) []ast.Expr {
+	callee := typeutil.StaticCallee(pass.TypesInfo, call)
+	if callee == nil {
+		return nil
+	}
+	fact := new(writesHeaderParams)
+	if !pass.ImportObjectFact(callee, fact) {
+		return nil
+	}
+	var args []ast.Expr
+	for _, param := range fact.Params {
+		if param < len(call.Args) {
+			args = append(args, call.Args[param])
+		}
+	}
+	return args
+}
+
+/**
+ * checks whether a header passed to a function that writes to it is derived by casting a real slice or string. If the
+ * header is itself a parameter of the calling function, it is checked at the calls of that function instead
+ */
+func argumentDerivedByCast(arg ast.Expr, call *ast.CallExpr, headers *headerDefinitions, stack []ast.Node) bool {
+	arg = ast.Unparen(arg)
+	if ident, ok := arg.(*ast.Ident); ok {
+		object := headers.pass.TypesInfo.ObjectOf(ident)
+		if object != nil && resolve.IsReflectHeader(object.Type()) {
+			return headers.derivedByCast(object, call, stack)
+		}
+	}
+	return headers.valueSafe(arg)
+}
@@ -0,0 +1,877 @@
+package sliceheader
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+
+	"github.com/jlauinger/go-safer/passes/internal/astedit"
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+)
+
+// minimum Go version that provides unsafe.Slice, unsafe.String, unsafe.StringData and unsafe.SliceData
+const unsafeSliceGoVersion = "go1.20"
+
+/**
+ * builds the suggested fixes for a reflect header composite literal. If the literal is the well-known string to []byte
+ * (or []byte to string) idiom and the module is recent enough, it is replaced with unsafe.Slice or unsafe.String.
+ * Otherwise, the header is backed by a real slice or string variable and filled field by field.
+ */
+func compositeLiteralFixes(cl *ast.CompositeLit, pass *analysis.Pass, stack []ast.Node) []analysis.SuggestedFix {
+	// the literal must directly define a header variable, either as a pointer (&T{...}) or as a value (T{...})
+	assignStmt, header, pointer, ok := literalHeaderDefinition(cl, stack)
+	if !ok {
+		return nil
+	}
+
+	// we need the fields of the literal by name to rewrite them
+	fields, ok := literalFields(cl)
+	if !ok {
+		return nil
+	}
+
+	// find the file and the function body that contain the literal, they are the scope of the rewrite
+	file := astedit.EnclosingFile(cl, pass)
+	body := astedit.EnclosingFuncBody(stack)
+	if file == nil || body == nil {
+		return nil
+	}
+
+	// the rewrites always need the unsafe package to be imported under a usable name
+	unsafeName, ok := astedit.ImportName(file, "unsafe")
+	if !ok {
+		return nil
+	}
+
+	// prefer the modern unsafe functions if the module is allowed to use them
+	if astedit.ModuleSupports(pass, unsafeSliceGoVersion) {
+		fix, ok := unsafeFunctionFix(cl, pass, file, body, assignStmt, header, pointer, fields, unsafeName)
+		if ok {
+			return []analysis.SuggestedFix{fix}
+		}
+	}
+
+	// otherwise fall back to backing the header with a real variable, which only works for pointer headers
+	if !pointer {
+		return nil
+	}
+	fix, ok := backedHeaderFix(cl, pass, body, assignStmt, header, fields, unsafeName)
+	if !ok {
+		return nil
+	}
+	return []analysis.SuggestedFix{fix}
+}
+
+/**
+ * checks that a composite literal is the single value in a header variable definition statement of the form
+ * hdr := &T{...} or hdr := T{...}, and returns the statement, the header identifier and whether it is a pointer
+ */
+func literalHeaderDefinition(cl *ast.CompositeLit, stack []ast.Node) (*ast.AssignStmt, *ast.Ident, bool, bool) {
+	// the stack ends with the literal itself, so the direct parent is the element before it
+	i := len(stack) - 2
+	if i < 0 {
+		return nil, nil, false, false
+	}
+
+	// skip over a potential & operator, remembering that the header is a pointer
+	pointer := false
+	unary, ok := stack[i].(*ast.UnaryExpr)
+	if ok && unary.Op == token.AND {
+		pointer = true
+		i--
+	}
+	if i < 1 {
+		return nil, nil, false, false
+	}
+
+	// the parent must be a defining assignment with exactly one target and value
+	assignStmt, ok := stack[i].(*ast.AssignStmt)
+	if !ok || assignStmt.Tok != token.DEFINE || len(assignStmt.Lhs) != 1 || len(assignStmt.Rhs) != 1 {
+		return nil, nil, false, false
+	}
+	header, ok := assignStmt.Lhs[0].(*ast.Ident)
+	if !ok {
+		return nil, nil, false, false
+	}
+
+	// the statement must be part of a block, so that it can be replaced by multiple statements
+	if _, ok := stack[i-1].(*ast.BlockStmt); !ok {
+		return nil, nil, false, false
+	}
+
+	return assignStmt, header, pointer, true
+}
+
+/**
+ * returns the elements of a composite literal mapped by their field name, if all elements are keyed
+ */
+func literalFields(cl *ast.CompositeLit) ([]*ast.KeyValueExpr, bool) {
+	fields := make([]*ast.KeyValueExpr, 0, len(cl.Elts))
+	for _, elt := range cl.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return nil, false
+		}
+		if _, ok := kv.Key.(*ast.Ident); !ok {
+			return nil, false
+		}
+		fields = append(fields, kv)
+	}
+	return fields, true
+}
+
+/**
+ * finds the value expression of a named field in a list of keyed literal elements
+ */
+func fieldValue(fields []*ast.KeyValueExpr, name string) ast.Expr {
+	for _, kv := range fields {
+		if kv.Key.(*ast.Ident).Name == name {
+			return kv.Value
+		}
+	}
+	return nil
+}
+
+/**
+ * builds a fix that replaces a string to []byte or []byte to string header literal with unsafe.Slice or unsafe.String
+ */
+func unsafeFunctionFix(cl *ast.CompositeLit, pass *analysis.Pass, file *ast.File, body *ast.BlockStmt,
+	assignStmt *ast.AssignStmt, header *ast.Ident, pointer bool, fields []*ast.KeyValueExpr,
+	unsafeName string) (analysis.SuggestedFix, bool) {
+	// a slice header literal is filled from a string, and a string header literal from a byte slice
+	sliceLiteral := resolve.ReflectHeaderName(pass.TypesInfo.TypeOf(cl)) == "SliceHeader"
+
+	// the Data field must be taken from another header variable
+	dataSelector, ok := fieldValue(fields, "Data").(*ast.SelectorExpr)
+	if !ok || dataSelector.Sel.Name != "Data" {
+		return analysis.SuggestedFix{}, false
+	}
+	sourceHeader, ok := dataSelector.X.(*ast.Ident)
+	if !ok {
+		return analysis.SuggestedFix{}, false
+	}
+	sourceHeaderObject := pass.TypesInfo.ObjectOf(sourceHeader)
+	if sourceHeaderObject == nil {
+		return analysis.SuggestedFix{}, false
+	}
+
+	// the length (and capacity, for slices) must be the length of that same header
+	if !selectsFieldOf(fieldValue(fields, "Len"), sourceHeaderObject, "Len", pass) {
+		return analysis.SuggestedFix{}, false
+	}
+	if sliceLiteral && !selectsFieldOf(fieldValue(fields, "Cap"), sourceHeaderObject, "Len", pass) {
+		return analysis.SuggestedFix{}, false
+	}
+
+	// find out where the source header was derived from, it must be a cast from a real string or byte slice
+	sourceHeaderStmt, source := headerDefinitionSource(sourceHeaderObject, body, pass)
+	if source == nil {
+		return analysis.SuggestedFix{}, false
+	}
+	sourceType := pass.TypesInfo.TypeOf(source)
+	if sourceType == nil {
+		return analysis.SuggestedFix{}, false
+	}
+	if sliceLiteral && !types.Identical(sourceType.Underlying(), types.Typ[types.String]) {
+		return analysis.SuggestedFix{}, false
+	}
+	if !sliceLiteral && !isByteSlice(sourceType) {
+		return analysis.SuggestedFix{}, false
+	}
+
+	// the header we are replacing must be used exactly once, in the final cast to the real slice or string
+	headerObject := pass.TypesInfo.Defs[header]
+	headerUses := usesOf(headerObject, body, pass)
+	if len(headerUses) != 1 {
+		return analysis.SuggestedFix{}, false
+	}
+	finalCast, _ := headerFinalCast(headerUses[0], file, pointer, pass)
+	if finalCast == nil {
+		return analysis.SuggestedFix{}, false
+	}
+	finalType := pass.TypesInfo.TypeOf(finalCast)
+	if sliceLiteral && !isByteSlice(finalType) {
+		return analysis.SuggestedFix{}, false
+	}
+	if !sliceLiteral && (finalType == nil || !types.Identical(finalType.Underlying(), types.Typ[types.String])) {
+		return analysis.SuggestedFix{}, false
+	}
+
+	// build the replacement expression from the original source
+	sourceText := astedit.Render(source, pass)
+	function := "String"
+	replacement := fmt.Sprintf("%s.String(%s.SliceData(%s), len(%s))", unsafeName, unsafeName, sourceText, sourceText)
+	if sliceLiteral {
+		function = "Slice"
+		replacement = fmt.Sprintf("%s.Slice(%s.StringData(%s), len(%s))", unsafeName, unsafeName, sourceText, sourceText)
+	}
+
+	// replace the final cast, and remove the literal definition
+	edits := []analysis.TextEdit{
+		{Pos: finalCast.Pos(), End: finalCast.End(), NewText: []byte(replacement)},
+		astedit.DeleteLines(assignStmt, pass),
+	}
+	removed := []ast.Node{assignStmt, finalCast}
+
+	// the source header is not needed anymore if it was only used in the literal
+	sourceHeaderUnused := true
+	for _, use := range usesOf(sourceHeaderObject, body, pass) {
+		if !astedit.Within(use, cl) {
+			sourceHeaderUnused = false
+		}
+	}
+	if sourceHeaderUnused {
+		edits = append(edits, astedit.DeleteLines(sourceHeaderStmt, pass))
+		removed = append(removed, sourceHeaderStmt)
+	}
+
+	// finally, the reflect import might have become unused now
+	edits = append(edits, astedit.RemoveUnusedImport(file, "reflect", removed, false, pass)...)
+
+	return analysis.SuggestedFix{
+		Message:   fmt.Sprintf("replace reflect header literal with unsafe.%s", function),
+		TextEdits: edits,
+	}, true
+}
+
+/**
+ * builds a fix that declares a real slice or string variable, derives the header from it by casting, and fills the
+ * header fields with the values from the literal
+ */
+func backedHeaderFix(cl *ast.CompositeLit, pass *analysis.Pass, body *ast.BlockStmt, assignStmt *ast.AssignStmt,
+	header *ast.Ident, fields []*ast.KeyValueExpr, unsafeName string) (analysis.SuggestedFix, bool) {
+	sliceLiteral := resolve.ReflectHeaderName(pass.TypesInfo.TypeOf(cl)) == "SliceHeader"
+
+	// the variable backing the header has the type that the header is finally cast to
+	headerObject, ok := pass.TypesInfo.Defs[header].(*types.Var)
+	if !ok {
+		return analysis.SuggestedFix{}, false
+	}
+	backingType := finalCastType(headerObject, astedit.EnclosingFile(cl, pass), body, sliceLiteral, pass)
+
+	// pick a name for the backing variable that does not shadow anything visible at the statement
+	baseName := "s"
+	if sliceLiteral {
+		baseName = "b"
+	}
+	backingName := freshName(baseName, assignStmt.Pos(), pass)
+
+	// build the replacement statements
+	indent := indentation(assignStmt, pass)
+	lines := []string{
+		fmt.Sprintf("var %s %s", backingName, astedit.Render(backingType, pass)),
+		fmt.Sprintf("%s := (*%s)(%s.Pointer(&%s))", header.Name, astedit.Render(cl.Type, pass), unsafeName, backingName),
+	}
+	for _, kv := range fields {
+		lines = append(lines, fmt.Sprintf("%s.%s = %s", header.Name, kv.Key.(*ast.Ident).Name, astedit.Render(kv.Value, pass)))
+	}
+
+	return analysis.SuggestedFix{
+		Message: "derive reflect header from a real variable",
+		TextEdits: []analysis.TextEdit{{
+			Pos:     assignStmt.Pos(),
+			End:     assignStmt.End(),
+			NewText: []byte(strings.Join(lines, "\n"+indent)),
+		}},
+	}, true
+}
+
+/**
+ * checks whether a type is a slice of bytes
+ */
+func isByteSlice(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	slice, ok := t.Underlying().(*types.Slice)
+	if !ok {
+		return false
+	}
+	elem, ok := slice.Elem().Underlying().(*types.Basic)
+	return ok && elem.Kind() == types.Byte
+}
+
+/**
+ * checks whether an expression selects the named field of the given object
+ */
+func selectsFieldOf(expr ast.Expr, object types.Object, field string, pass *analysis.Pass) bool {
+	selector, ok := expr.(*ast.SelectorExpr)
+	if !ok || selector.Sel.Name != field {
+		return false
+	}
+	ident, ok := selector.X.(*ast.Ident)
+	return ok && pass.TypesInfo.ObjectOf(ident) == object
+}
+
+/**
+ * finds the statement defining a header object by casting a real slice or string, and returns the statement together
+ * with the expression of the real slice or string
+ */
+func headerDefinitionSource(object types.Object, body *ast.BlockStmt, pass *analysis.Pass) (ast.Stmt, ast.Expr) {
+	var stmt ast.Stmt
+	var source ast.Expr
+	ast.Inspect(body, func(n ast.Node) bool {
+		if source != nil {
+			return false
+		}
+		// look for the defining assignment of the object
+		assigns, valueExpr := nodeAssignsObject(n, object, pass)
+		if !assigns || !definitionExprIsCastFromRealSlice(valueExpr, pass) {
+			return true
+		}
+		// the value is (*T)(unsafe.Pointer(&x)), possibly dereferenced, so extract x
+		if starExpr, ok := valueExpr.(*ast.StarExpr); ok {
+			valueExpr = starExpr.X
+		}
+		pointerCast := valueExpr.(*ast.CallExpr).Args[0].(*ast.CallExpr)
+		addressOf, ok := pointerCast.Args[0].(*ast.UnaryExpr)
+		if !ok || addressOf.Op != token.AND {
+			return true
+		}
+		// only defining statements on their own can be removed later
+		if len(n.(*ast.AssignStmt).Lhs) != 1 {
+			return true
+		}
+		stmt = n.(*ast.AssignStmt)
+		source = addressOf.X
+		return false
+	})
+	return stmt, source
+}
+
+/**
+ * finds the final cast *(*T)(unsafe.Pointer(hdr)) (or &hdr, for value headers) that turns a header into a real slice
+ * or string, and returns the whole cast expression together with the target type expression T
+ */
+func headerFinalCast(use *ast.Ident, file *ast.File, pointer bool, pass *analysis.Pass) (*ast.StarExpr, ast.Expr) {
+	path, _ := astutil.PathEnclosingInterval(file, use.Pos(), use.End())
+	// path starts with the identifier itself, continue with its parents
+	i := 1
+	if !pointer {
+		// value headers have their address taken
+		if i >= len(path) {
+			return nil, nil
+		}
+		unary, ok := path[i].(*ast.UnaryExpr)
+		if !ok || unary.Op != token.AND {
+			return nil, nil
+		}
+		i++
+	}
+
+	// then, they must be converted to unsafe.Pointer
+	if i+2 >= len(path) {
+		return nil, nil
+	}
+	pointerCall, ok := path[i].(*ast.CallExpr)
+	if !ok || !resolve.IsUnsafePointerConversion(pass.TypesInfo, pointerCall) {
+		return nil, nil
+	}
+
+	// then cast to a pointer type and dereferenced
+	targetCall, ok := path[i+1].(*ast.CallExpr)
+	if !ok || len(targetCall.Args) != 1 {
+		return nil, nil
+	}
+	targetParen, ok := targetCall.Fun.(*ast.ParenExpr)
+	if !ok {
+		return nil, nil
+	}
+	targetStar, ok := targetParen.X.(*ast.StarExpr)
+	if !ok {
+		return nil, nil
+	}
+	deref, ok := path[i+2].(*ast.StarExpr)
+	if !ok {
+		return nil, nil
+	}
+	return deref, targetStar.X
+}
+
+/**
+ * returns all identifiers within a node that refer to the given object
+ */
+func usesOf(object types.Object, root ast.Node, pass *analysis.Pass) []*ast.Ident {
+	var uses []*ast.Ident
+	if object == nil {
+		return uses
+	}
+	ast.Inspect(root, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if ok && pass.TypesInfo.Uses[ident] == object {
+			uses = append(uses, ident)
+		}
+		return true
+	})
+	return uses
+}
+
+/**
+ * returns the whitespace in front of a node on its line
+ */
+func indentation(n ast.Node, pass *analysis.Pass) string {
+	tokFile := pass.Fset.File(n.Pos())
+	content, err := pass.ReadFile(tokFile.Name())
+	if err != nil {
+		return ""
+	}
+	lineStart := tokFile.Offset(tokFile.LineStart(tokFile.Line(n.Pos())))
+	prefix := content[lineStart:tokFile.Offset(n.Pos())]
+	if len(bytes.TrimSpace(prefix)) != 0 {
+		return ""
+	}
+	return string(prefix)
+}
+
+/**
+ * returns a variable name based on the given name that is not yet visible at the given position
+ */
+func freshName(base string, pos token.Pos, pass *analysis.Pass) string {
+	scope := pass.Pkg.Scope().Innermost(pos)
+	name := base
+	for i := 1; scope != nil; i++ {
+		if _, object := scope.LookupParent(name, pos); object == nil {
+			break
+		}
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	return name
+}
+
+/**
+ * builds the suggested fixes for an assignment to an incorrectly derived reflect header. The nil, zero value or new()
+ * initialisation of the header variable is replaced by a cast from a named result, a slice or string that is used
+ * after the header is filled, or a freshly declared one, and a runtime.KeepAlive call for the source of the Data field
+ * is added if it is missing.
+ */
+func assignmentFixes(stmt ast.Node, pass *analysis.Pass, stack []ast.Node,
+	fixedHeaders map[types.Object]bool) []analysis.SuggestedFix {
+	// find the header variable that is assigned to
+	header := assignedHeader(fieldWriteTargets(stmt), pass)
+	if header == nil || fixedHeaders[header] {
+		return nil
+	}
+	fixedHeaders[header] = true
+
+	// find the file and the function body that contain the assignment, they are the scope of the rewrite
+	file := astedit.EnclosingFile(stmt, pass)
+	body := astedit.EnclosingFuncBody(stack)
+	if file == nil || body == nil {
+		return nil
+	}
+	unsafeName, ok := astedit.ImportName(file, "unsafe")
+	if !ok {
+		return nil
+	}
+
+	// find the statement that initialises the header without a real slice or string, and the header type used there
+	definitionStmt, headerType := headerZeroDefinition(header, body, pass)
+	if definitionStmt == nil {
+		return nil
+	}
+
+	// if the header is assigned again later, replacing the initialisation alone would not be enough
+	if headerReassigned(header, body, pass) {
+		return nil
+	}
+	sliceHeader := resolve.ReflectHeaderName(header.Type()) == "SliceHeader"
+	castType := finalCastType(header, file, body, sliceHeader, pass)
+
+	// prefer casting a named result of the function, because that is what the function returns anyway. Then, a
+	// variable that is used after the header is filled, like b in result <- b. Otherwise, declare a new variable in
+	// front of the header
+	var lines []string
+	backingName := namedResult(stack, castType, pass)
+	if backingName == "" {
+		backingName, ok = usedBacking(header, definitionStmt, castType, sliceHeader, file, body, pass)
+		if !ok {
+			return nil
+		}
+	}
+	if backingName == "" {
+		baseName := "s"
+		if sliceHeader {
+			baseName = "b"
+		}
+		backingName = freshName(baseName, definitionStmt.Pos(), pass)
+		lines = append(lines, fmt.Sprintf("var %s %s", backingName, astedit.Render(castType, pass)))
+	}
+	lines = append(lines, fmt.Sprintf("%s := (*%s)(%s.Pointer(&%s))", header.Name(), astedit.Render(headerType, pass),
+		unsafeName, backingName))
+
+	edits := []analysis.TextEdit{{
+		Pos:     definitionStmt.Pos(),
+		End:     definitionStmt.End(),
+		NewText: []byte(strings.Join(lines, "\n"+indentation(definitionStmt, pass))),
+	}}
+
+	// keep the source of the Data field alive until the header is filled, if that is not done already
+	edits = append(edits, keepAliveEdits(header, file, body, pass)...)
+
+	return []analysis.SuggestedFix{{
+		Message:   "derive reflect header by casting a real slice or string",
+		TextEdits: edits,
+	}}
+}
+
+/**
+ * returns the header variable object that is the target of a field assignment
+ */
+func assignedHeader(targets []ast.Expr, pass *analysis.Pass) *types.Var {
+	for _, expr := range targets {
+		lhs, ok := expr.(*ast.SelectorExpr)
+		if !ok {
+			continue
+		}
+		lhsIdent, ok := lhs.X.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		// only pointer headers can be redirected to a real slice or string by a cast
+		variable, ok := pass.TypesInfo.ObjectOf(lhsIdent).(*types.Var)
+		if !ok {
+			continue
+		}
+		if _, ok := variable.Type().Underlying().(*types.Pointer); ok && resolve.IsReflectHeader(variable.Type()) {
+			return variable
+		}
+	}
+	return nil
+}
+
+/**
+ * finds the statement that defines a header variable without deriving it from a real slice or string, i.e. as
+ * (*T)(nil), (*T)(unsafe.Pointer(nil)), new(T) or var h *T, and returns it together with the header type expression T
+ */
+func headerZeroDefinition(header *types.Var, body *ast.BlockStmt, pass *analysis.Pass) (ast.Stmt, ast.Expr) {
+	var stmt ast.Stmt
+	var headerType ast.Expr
+	ast.Inspect(body, func(n ast.Node) bool {
+		if stmt != nil {
+			return false
+		}
+		switch s := n.(type) {
+		case *ast.AssignStmt:
+			// a defining assignment of only the header
+			if len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+				return true
+			}
+			ident, ok := s.Lhs[0].(*ast.Ident)
+			if !ok || pass.TypesInfo.Defs[ident] != header {
+				return true
+			}
+			headerType = zeroHeaderValueType(s.Rhs[0], pass)
+			if headerType != nil {
+				stmt = s
+			}
+		case *ast.DeclStmt:
+			// a variable declaration of only the header, without a value
+			genDecl, ok := s.Decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.VAR || len(genDecl.Specs) != 1 {
+				return true
+			}
+			valueSpec := genDecl.Specs[0].(*ast.ValueSpec)
+			if len(valueSpec.Names) != 1 || len(valueSpec.Values) != 0 || pass.TypesInfo.Defs[valueSpec.Names[0]] != header {
+				return true
+			}
+			starExpr, ok := valueSpec.Type.(*ast.StarExpr)
+			if ok {
+				stmt = s
+				headerType = starExpr.X
+			}
+		}
+		return true
+	})
+	return stmt, headerType
+}
+
+/**
+ * checks whether a header variable is assigned to after its definition
+ */
+func headerReassigned(header *types.Var, body *ast.BlockStmt, pass *analysis.Pass) bool {
+	reassigned := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		assignStmt, ok := n.(*ast.AssignStmt)
+		if !ok {
+			return !reassigned
+		}
+		for _, lhs := range assignStmt.Lhs {
+			ident, ok := lhs.(*ast.Ident)
+			if ok && pass.TypesInfo.Uses[ident] == header {
+				reassigned = true
+			}
+		}
+		return !reassigned
+	})
+	return reassigned
+}
+
+/**
+ * checks whether an expression creates a header pointer that does not point to a real slice or string, and returns
+ * the header type expression if so
+ */
+func zeroHeaderValueType(expr ast.Expr, pass *analysis.Pass) ast.Expr {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return nil
+	}
+
+	// new(T)
+	if ident, ok := call.Fun.(*ast.Ident); ok {
+		if builtin, ok := pass.TypesInfo.Uses[ident].(*types.Builtin); ok && builtin.Name() == "new" {
+			return call.Args[0]
+		}
+		return nil
+	}
+
+	// (*T)(nil) or (*T)(unsafe.Pointer(nil))
+	paren, ok := call.Fun.(*ast.ParenExpr)
+	if !ok {
+		return nil
+	}
+	starExpr, ok := paren.X.(*ast.StarExpr)
+	if !ok {
+		return nil
+	}
+	arg := call.Args[0]
+	if pointerCall, ok := arg.(*ast.CallExpr); ok && resolve.IsUnsafePointerConversion(pass.TypesInfo, pointerCall) {
+		arg = pointerCall.Args[0]
+	}
+	if tv, ok := pass.TypesInfo.Types[arg]; !ok || !tv.IsNil() {
+		return nil
+	}
+	return starExpr.X
+}
+
+/**
+ * finds the type that a pointer header is finally cast to, e.g. []byte in *(*[]byte)(unsafe.Pointer(h)). If there is
+ * no such cast, []byte or string is assumed depending on the header type
+ */
+func finalCastType(header *types.Var, file *ast.File, body *ast.BlockStmt, sliceHeader bool,
+	pass *analysis.Pass) ast.Expr {
+	for _, use := range usesOf(header, body, pass) {
+		_, castType := headerFinalCast(use, file, true, pass)
+		if castType != nil {
+			return castType
+		}
+	}
+	if sliceHeader {
+		return &ast.ArrayType{Elt: ast.NewIdent("byte")}
+	}
+	return ast.NewIdent("string")
+}
+
+/**
+ * returns the name of a named result of the innermost function on an AST stack that has the given type
+ */
+func namedResult(stack []ast.Node, resultType ast.Expr, pass *analysis.Pass) string {
+	var funcType *ast.FuncType
+	for i := len(stack) - 1; i >= 0 && funcType == nil; i-- {
+		switch f := stack[i].(type) {
+		case *ast.FuncDecl:
+			funcType = f.Type
+		case *ast.FuncLit:
+			funcType = f.Type
+		}
+	}
+	if funcType == nil || funcType.Results == nil {
+		return ""
+	}
+
+	// the cast type might be synthesized, in that case compare the source text
+	wantedType := pass.TypesInfo.TypeOf(resultType)
+	for _, field := range funcType.Results.List {
+		for _, name := range field.Names {
+			object := pass.TypesInfo.Defs[name]
+			if object == nil || name.Name == "_" {
+				continue
+			}
+			if wantedType != nil && types.Identical(object.Type(), wantedType) {
+				return name.Name
+			}
+			if wantedType == nil && astedit.Render(field.Type, pass) == astedit.Render(resultType, pass) {
+				return name.Name
+			}
+		}
+	}
+	return ""
+}
+
+/**
+ * finds the variable that a header is meant to fill if it is never cast back into a slice or string, i.e. a variable
+ * of the cast type that is visible at the header definition and used after the header is written to. The name is
+ * empty if there is no such variable, and the last return value is false if it is ambiguous or must not be cast
+ */
+func usedBacking(header *types.Var, definitionStmt ast.Stmt, castType ast.Expr, sliceHeader bool, file *ast.File,
+	body *ast.BlockStmt, pass *analysis.Pass) (string, bool) {
+	// a final cast of the header holds the result, and a new variable can back it
+	headerUses := usesOf(header, body, pass)
+	lastWrite := definitionStmt.End()
+	for _, use := range headerUses {
+		if _, target := headerFinalCast(use, file, true, pass); target != nil {
+			return "", true
+		}
+		if use.End() > lastWrite {
+			lastWrite = use.End()
+		}
+	}
+
+	// the cast type might be synthesized, in that case it has no type information
+	wantedType := pass.TypesInfo.TypeOf(castType)
+	if wantedType == nil {
+		wantedType = types.Typ[types.String]
+		if sliceHeader {
+			wantedType = types.NewSlice(types.Typ[types.Byte])
+		}
+	}
+	scope := pass.Pkg.Scope().Innermost(definitionStmt.Pos())
+	if scope == nil {
+		return "", false
+	}
+
+	// collect the variables of that type which are used after the header writes under the same name as at the
+	// definition. Keeping the source of the Data field alive does not use it
+	var candidate *types.Var
+	ambiguous := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && resolve.IsPackageObject(pass.TypesInfo, call.Fun, "runtime", "KeepAlive") {
+			return false
+		}
+		ident, ok := n.(*ast.Ident)
+		if !ok || ident.Pos() < lastWrite {
+			return true
+		}
+		variable, ok := pass.TypesInfo.Uses[ident].(*types.Var)
+		if !ok || variable == candidate || !types.Identical(variable.Type(), wantedType) {
+			return true
+		}
+		if _, object := scope.LookupParent(variable.Name(), definitionStmt.Pos()); object != variable {
+			return true
+		}
+		ambiguous = ambiguous || candidate != nil
+		candidate = variable
+		return true
+	})
+	if ambiguous {
+		return "", false
+	}
+	if candidate == nil {
+		return "", true
+	}
+
+	// a variable whose address is taken might back another header already, like the source of the Data field
+	addressTaken := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		unary, ok := n.(*ast.UnaryExpr)
+		if ok && unary.Op == token.AND {
+			if ident, ok := ast.Unparen(unary.X).(*ast.Ident); ok && pass.TypesInfo.Uses[ident] == candidate {
+				addressTaken = true
+			}
+		}
+		return !addressTaken
+	})
+	if addressTaken {
+		return "", false
+	}
+	return candidate.Name(), true
+}
+
+/**
+ * builds the edits that add a runtime.KeepAlive call for the object whose header Data field is copied into the given
+ * header, right after the copy, if the function does not keep the object alive already
+ */
+func keepAliveEdits(header *types.Var, file *ast.File, body *ast.BlockStmt, pass *analysis.Pass) []analysis.TextEdit {
+	// find the assignment that copies the Data field from another header
+	var dataStmt *ast.AssignStmt
+	var sourceHeader types.Object
+	ast.Inspect(body, func(n ast.Node) bool {
+		assignStmt, ok := n.(*ast.AssignStmt)
+		if !ok || dataStmt != nil || len(assignStmt.Lhs) != 1 || len(assignStmt.Rhs) != 1 {
+			return dataStmt == nil
+		}
+		if !selectsFieldOf(assignStmt.Lhs[0], header, "Data", pass) {
+			return true
+		}
+		rhs, ok := assignStmt.Rhs[0].(*ast.SelectorExpr)
+		if !ok || rhs.Sel.Name != "Data" {
+			return true
+		}
+		rhsIdent, ok := rhs.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		dataStmt = assignStmt
+		sourceHeader = pass.TypesInfo.ObjectOf(rhsIdent)
+		return false
+	})
+	if dataStmt == nil || sourceHeader == nil {
+		return nil
+	}
+
+	// the other header must be derived from a real object that we can refer to by name
+	_, source := headerDefinitionSource(sourceHeader, body, pass)
+	sourceIdent, ok := source.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	sourceObject := pass.TypesInfo.ObjectOf(sourceIdent)
+
+	// package-level variables are never collected
+	if sourceObject == nil || sourceObject.Parent() == pass.Pkg.Scope() {
+		return nil
+	}
+
+	// check if the source object is already kept alive
+	keptAlive := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 || !resolve.IsPackageObject(pass.TypesInfo, call.Fun, "runtime", "KeepAlive") {
+			return true
+		}
+		if arg, ok := call.Args[0].(*ast.Ident); ok && pass.TypesInfo.ObjectOf(arg) == sourceObject {
+			keptAlive = true
+		}
+		return true
+	})
+	if keptAlive {
+		return nil
+	}
+
+	// the call can only be inserted if the Data assignment is a statement in a block
+	path, _ := astutil.PathEnclosingInterval(file, dataStmt.Pos(), dataStmt.End())
+	if len(path) < 2 {
+		return nil
+	}
+	if _, ok := path[1].(*ast.BlockStmt); !ok {
+		return nil
+	}
+
+	// use the runtime package, importing it if necessary
+	var edits []analysis.TextEdit
+	runtimeName, ok := astedit.ImportName(file, "runtime")
+	if !ok {
+		for _, importSpec := range file.Imports {
+			if importSpec.Path.Value == `"runtime"` {
+				// imported, but not usable by name
+				return nil
+			}
+		}
+		runtimeName = "runtime"
+		edits = append(edits, astedit.AddImport(file, "runtime", pass))
+	}
+
+	// insert the call on its own line after the Data assignment
+	insertPos := astedit.DeleteLines(dataStmt, pass).End
+	edits = append(edits, analysis.TextEdit{
+		Pos:     insertPos,
+		End:     insertPos,
+		NewText: []byte(fmt.Sprintf("%s%s.KeepAlive(%s)\n", indentation(dataStmt, pass), runtimeName, sourceIdent.Name)),
+	})
+	return edits
+}
@@ -0,0 +1,479 @@
+package sliceheader
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/ctrlflow"
+	"golang.org/x/tools/go/cfg"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+)
+
+// headerDefinitions finds the definitions of reflect header variables in a package. The reaching definitions are
+// computed lazily once per function, and the definitions of package-level header variables once per package
+type headerDefinitions struct {
+	pass      *analysis.Pass
+	cfgs      *ctrlflow.CFGs
+	functions map[ast.Node]*reachingDefinitions
+	// whether each package-level header variable is only ever assigned safe casts, nil until computed
+	globals map[types.Object]bool
+}
+
+/**
+ * creates a new lookup for reflect header definitions in the package under analysis
+ */
+func newHeaderDefinitions(pass *analysis.Pass, cfgs *ctrlflow.CFGs) *headerDefinitions {
+	return &headerDefinitions{
+		pass:      pass,
+		cfgs:      cfgs,
+		functions: make(map[ast.Node]*reachingDefinitions),
+	}
+}
+
+/**
+ * checks if an object is derived with a cast from a real slice or string on all paths leading to a node. The stack
+ * contains the AST nodes enclosing the node
+ */
+func (h *headerDefinitions) derivedByCast(object types.Object, n ast.Node, stack []ast.Node) bool {
+	// package-level variables can be assigned anywhere in the package, so they are checked as a whole
+	if object.Parent() == h.pass.Pkg.Scope() {
+		return h.globalDerivedByCast(object)
+	}
+
+	// find the definitions that may define the value of the object at the node
+	definitions, ok := h.reaching(object, n, stack)
+
+	// if we cannot find an assignment to the object, we infer it was not derived safely by a cast
+	if !ok || len(definitions) == 0 {
+		return false
+	}
+
+	// otherwise, every single one of them must be a cast from a real slice or string. Header pointer parameters of
+	// declared functions are checked at the call sites instead
+	for _, d := range definitions {
+		if !h.safe(d) && d.param < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+ * returns the indices of the header pointer parameters of the enclosing declared function whose value may reach a node
+ * as the value of an object
+ */
+func (h *headerDefinitions) reachingParameters(object types.Object, n ast.Node, stack []ast.Node) []int {
+	var params []int
+	definitions, _ := h.reaching(object, n, stack)
+	for _, d := range definitions {
+		if d.param >= 0 {
+			params = append(params, d.param)
+		}
+	}
+	return params
+}
+
+/**
+ * returns the definitions of an object that reach a node within the innermost function on the stack. The second return
+ * value is false if the node can not be located in the control flow graph of the function
+ */
+func (h *headerDefinitions) reaching(object types.Object, n ast.Node, stack []ast.Node) ([]*definition, bool) {
+	// find the innermost function containing the node, which is either a declared function or a closure
+	i := innermostFunction(stack)
+	if i < 0 {
+		return nil, false
+	}
+	rd := h.forFunction(stack[:i+1])
+	if rd == nil {
+		return nil, false
+	}
+
+	// the node might be an expression within a statement, then use the innermost enclosing node of the graph
+	if _, ok := rd.positions[n]; !ok {
+		for j := len(stack) - 1; j > i; j-- {
+			if _, ok := rd.positions[stack[j]]; ok {
+				n = stack[j]
+				break
+			}
+		}
+	}
+	return rd.reaching(n, object)
+}
+
+/**
+ * checks whether a definition assigns a value that is derived from a real slice or string
+ */
+func (h *headerDefinitions) safe(d *definition) bool {
+	return d.value != nil && h.valueSafe(d.value)
+}
+
+/**
+ * checks whether an expression evaluates to a header that is derived from a real slice or string, that is either a
+ * cast or a call to a function that is known to return such headers
+ */
+func (h *headerDefinitions) valueSafe(expr ast.Expr) bool {
+	if definitionExprIsCastFromRealSlice(expr, h.pass) {
+		return true
+	}
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	callee := typeutil.StaticCallee(h.pass.TypesInfo, call)
+	return callee != nil && h.pass.ImportObjectFact(callee, new(returnsSafeHeader))
+}
+
+/**
+ * returns the reaching definitions of the function at the end of the stack, computing them if necessary
+ */
+func (h *headerDefinitions) forFunction(stack []ast.Node) *reachingDefinitions {
+	function := stack[len(stack)-1]
+	if rd, ok := h.functions[function]; ok {
+		return rd
+	}
+
+	var rd *reachingDefinitions
+	switch f := function.(type) {
+	case *ast.FuncDecl:
+		if g := h.cfgs.FuncDecl(f); g != nil {
+			rd = newReachingDefinitions(g, f.Type, nil, true, h.pass)
+		}
+	case *ast.FuncLit:
+		// closures start with the definitions of captured variables that reach the closure in the outer function
+		if g := h.cfgs.FuncLit(f); g != nil {
+			rd = newReachingDefinitions(g, f.Type, h.capturedDefinitions(f, stack), false, h.pass)
+		}
+	}
+
+	h.functions[function] = rd
+	return rd
+}
+
+/**
+ * returns the definitions of reflect header variables captured by a closure that reach the closure in its enclosing
+ * function. The stack ends with the closure
+ */
+func (h *headerDefinitions) capturedDefinitions(lit *ast.FuncLit, stack []ast.Node) []*definition {
+	// closures in package-level variable initializers can only capture package-level variables
+	i := innermostFunction(stack[:len(stack)-1])
+	if i < 0 {
+		return nil
+	}
+	outer := h.forFunction(stack[:i+1])
+	if outer == nil {
+		return nil
+	}
+
+	// find the node of the outer control flow graph that contains the closure, e.g. a go or defer statement
+	var node ast.Node
+	for j := len(stack) - 2; j > i && node == nil; j-- {
+		if _, ok := outer.positions[stack[j]]; ok {
+			node = stack[j]
+		}
+	}
+	if node == nil {
+		return nil
+	}
+
+	// collect the local header variables of the outer function that are used within the closure
+	captured := make(map[types.Object]bool)
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		object, ok := h.pass.TypesInfo.Uses[ident].(*types.Var)
+		if ok && resolve.IsReflectHeader(object.Type()) && object.Parent() != h.pass.Pkg.Scope() &&
+			(object.Pos() < lit.Pos() || object.Pos() >= lit.End()) {
+			captured[object] = true
+		}
+		return true
+	})
+
+	var definitions []*definition
+	for object := range captured {
+		reaching, _ := outer.reaching(node, object)
+		definitions = append(definitions, reaching...)
+	}
+	return definitions
+}
+
+/**
+ * checks whether a package-level header variable is initialized and assigned with safe casts only
+ */
+func (h *headerDefinitions) globalDerivedByCast(object types.Object) bool {
+	if h.globals == nil {
+		h.globals = make(map[types.Object]bool)
+
+		// the initializers of package-level variables define them first
+		for _, file := range h.pass.Files {
+			for _, decl := range file.Decls {
+				genDecl, ok := decl.(*ast.GenDecl)
+				if !ok || genDecl.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range genDecl.Specs {
+					for _, assigned := range assignedHeaderObjects(spec, h.pass) {
+						h.globals[assigned.object] = assigned.value != nil && h.valueSafe(assigned.value)
+					}
+				}
+			}
+		}
+
+		// then, any assignment within the package can redefine them
+		for _, file := range h.pass.Files {
+			ast.Inspect(file, func(n ast.Node) bool {
+				if _, ok := n.(*ast.AssignStmt); !ok {
+					return true
+				}
+				for _, assigned := range assignedHeaderObjects(n, h.pass) {
+					if _, ok := h.globals[assigned.object]; ok && (assigned.value == nil || !h.valueSafe(assigned.value)) {
+						h.globals[assigned.object] = false
+					}
+				}
+				return true
+			})
+		}
+	}
+	return h.globals[object]
+}
+
+/**
+ * returns the index of the innermost function declaration or closure on an AST stack, or -1 if there is none
+ */
+func innermostFunction(stack []ast.Node) int {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch stack[i].(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			return i
+		}
+	}
+	return -1
+}
+
+// definition is a single assignment of a value to a reflect header variable
+type definition struct {
+	object types.Object
+	// value that is assigned, or nil if it is unknown, e.g. for parameters or declarations without a value
+	value ast.Expr
+	// index of the parameter for the entry definitions of header pointer parameters of declared functions, -1 otherwise
+	param int
+}
+
+// nodePosition locates a node within the blocks of a control flow graph
+type nodePosition struct {
+	block *cfg.Block
+	index int
+}
+
+// reachingDefinitions holds the result of a reaching definitions dataflow analysis on the reflect header variables of
+// a function
+type reachingDefinitions struct {
+	definitions []*definition
+	// the definitions created by each node in the control flow graph
+	generated map[ast.Node][]int
+	// the definitions that reach the start of each block
+	in map[*cfg.Block][]bool
+	// where each node is located in the control flow graph
+	positions map[ast.Node]nodePosition
+}
+
+/**
+ * computes the reaching definitions of all reflect header variables in a function, given its control flow graph, its
+ * signature, and for closures the definitions of captured variables. For declared functions, the definitions of
+ * header pointer parameters are marked with their parameter index
+ */
+func newReachingDefinitions(g *cfg.CFG, funcType *ast.FuncType, captured []*definition, declared bool,
+	pass *analysis.Pass) *reachingDefinitions {
+	rd := &reachingDefinitions{
+		generated: make(map[ast.Node][]int),
+		in:        make(map[*cfg.Block][]bool),
+		positions: make(map[ast.Node]nodePosition),
+	}
+
+	// captured variables are defined at function entry by the outer function
+	var entryDefinitions []int
+	for _, d := range captured {
+		rd.definitions = append(rd.definitions, d)
+		entryDefinitions = append(entryDefinitions, len(rd.definitions)-1)
+	}
+
+	// parameters and results are defined with an unknown value at function entry
+	for _, fields := range []*ast.FieldList{funcType.Params, funcType.Results} {
+		if fields == nil {
+			continue
+		}
+		index := 0
+		for _, field := range fields.List {
+			for _, name := range field.Names {
+				object := pass.TypesInfo.Defs[name]
+				if object != nil && resolve.IsReflectHeader(object.Type()) {
+					// remember which parameter it is, if it can be written to through the pointer by the caller
+					param := -1
+					if _, ok := object.Type().Underlying().(*types.Pointer); ok && declared && fields == funcType.Params {
+						param = index
+					}
+					entryDefinitions = append(entryDefinitions, rd.add(object, nil, param))
+				}
+				index++
+			}
+			// unnamed parameters still take up an index
+			if len(field.Names) == 0 {
+				index++
+			}
+		}
+	}
+
+	// collect the definitions made by each node in the graph
+	for _, block := range g.Blocks {
+		for i, n := range block.Nodes {
+			rd.positions[n] = nodePosition{block: block, index: i}
+			for _, object := range assignedHeaderObjects(n, pass) {
+				rd.generated[n] = append(rd.generated[n], rd.add(object.object, object.value, -1))
+			}
+		}
+	}
+
+	// initialize the entry block with the parameter definitions, and all other blocks with the empty set
+	for _, block := range g.Blocks {
+		rd.in[block] = make([]bool, len(rd.definitions))
+	}
+	if len(g.Blocks) > 0 {
+		for _, i := range entryDefinitions {
+			rd.in[g.Blocks[0]][i] = true
+		}
+	}
+
+	// iterate until the sets do not change anymore. The sets only grow, so this terminates
+	worklist := append([]*cfg.Block{}, g.Blocks...)
+	for len(worklist) > 0 {
+		block := worklist[0]
+		worklist = worklist[1:]
+
+		// the definitions leaving the block flow into all successors
+		out := rd.transfer(rd.in[block], block.Nodes)
+		for _, succ := range block.Succs {
+			changed := false
+			for i, reaches := range out {
+				if reaches && !rd.in[succ][i] {
+					rd.in[succ][i] = true
+					changed = true
+				}
+			}
+			if changed {
+				worklist = append(worklist, succ)
+			}
+		}
+	}
+
+	return rd
+}
+
+/**
+ * adds a definition and returns its index
+ */
+func (rd *reachingDefinitions) add(object types.Object, value ast.Expr, param int) int {
+	rd.definitions = append(rd.definitions, &definition{
+		object: object,
+		value:  value,
+		param:  param,
+	})
+	return len(rd.definitions) - 1
+}
+
+/**
+ * applies the definitions made by a sequence of nodes to a set of reaching definitions, and returns the new set
+ */
+func (rd *reachingDefinitions) transfer(in []bool, nodes []ast.Node) []bool {
+	out := append([]bool{}, in...)
+	for _, n := range nodes {
+		for _, i := range rd.generated[n] {
+			// a new definition of an object kills all previous ones
+			for j, other := range rd.definitions {
+				if other.object == rd.definitions[i].object {
+					out[j] = false
+				}
+			}
+		}
+		for _, i := range rd.generated[n] {
+			out[i] = true
+		}
+	}
+	return out
+}
+
+/**
+ * returns the definitions of an object that reach a node, i.e. that may be the current value when the node executes.
+ * The second return value is false if the node is not part of the control flow graph
+ */
+func (rd *reachingDefinitions) reaching(n ast.Node, object types.Object) ([]*definition, bool) {
+	position, ok := rd.positions[n]
+	if !ok {
+		return nil, false
+	}
+
+	// start from the definitions reaching the block, and apply the nodes in front of the node
+	set := rd.transfer(rd.in[position.block], position.block.Nodes[:position.index])
+
+	var definitions []*definition
+	for i, reaches := range set {
+		if reaches && rd.definitions[i].object == object {
+			definitions = append(definitions, rd.definitions[i])
+		}
+	}
+	return definitions, true
+}
+
+// assignedObject is a reflect header object together with the value assigned to it in a node
+type assignedObject struct {
+	object types.Object
+	value  ast.Expr
+}
+
+/**
+ * returns the reflect header objects that a CFG node assigns to, together with the assigned values
+ */
+func assignedHeaderObjects(n ast.Node, pass *analysis.Pass) []assignedObject {
+	var assigned []assignedObject
+
+	switch node := n.(type) {
+	case *ast.AssignStmt:
+		// both defining and plain assignments to identifiers count
+		for i, lhs := range node.Lhs {
+			lhsIdent, ok := lhs.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			object := pass.TypesInfo.ObjectOf(lhsIdent)
+			if object == nil || !resolve.IsReflectHeader(object.Type()) {
+				continue
+			}
+			// with multiple values on the right hand side, take the matching one. A single call returning multiple
+			// values can not be analyzed
+			var value ast.Expr
+			if len(node.Lhs) == len(node.Rhs) {
+				value = node.Rhs[i]
+			}
+			assigned = append(assigned, assignedObject{object: object, value: value})
+		}
+	case *ast.ValueSpec:
+		// variable declarations, possibly without value (then it is the zero value, i.e. not a real slice)
+		for i, name := range node.Names {
+			object := pass.TypesInfo.Defs[name]
+			if object == nil || !resolve.IsReflectHeader(object.Type()) {
+				continue
+			}
+			var value ast.Expr
+			if len(node.Names) == len(node.Values) {
+				value = node.Values[i]
+			}
+			assigned = append(assigned, assignedObject{object: object, value: value})
+		}
+	}
+
+	return assigned
+}
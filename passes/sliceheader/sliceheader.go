@@ -2,14 +2,14 @@ package sliceheader
 
 import (
 	"go/ast"
-	"go/token"
 	"go/types"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/ctrlflow"
 	"golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/ast/inspector"
-	"golang.org/x/tools/go/cfg"
+
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
 )
 
 // Analyzer is a golang.org/x/tools/go/analysis style linter pass.
@@ -20,6 +20,7 @@ var Analyzer = &analysis.Analyzer{
 	Run:              run,
 	Requires:         []*analysis.Analyzer{inspect.Analyzer, ctrlflow.Analyzer},
 	RunDespiteErrors: true,
+	FactTypes:        []analysis.Fact{new(writesHeaderParams), new(returnsSafeHeader)},
 }
 
 /**
@@ -30,61 +31,72 @@ func run(pass *analysis.Pass) (interface{}, error) {
 	inspectResult := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 	cfgResult := pass.ResultOf[ctrlflow.Analyzer].(*ctrlflow.CFGs)
 
+	// the reaching definitions of the reflect header variables are computed once per function and reused for all
+	// assignments within it
+	headers := newHeaderDefinitions(pass, cfgResult)
+
+	// first, find out which functions write to header parameters or return safe headers, so that calls to them can
+	// be checked
+	exportHeaderFacts(inspectResult, headers, pass)
+
 	// filter AST of package under analysis for composite literal nodes, which are the first possible node to find a
 	// slice header misuse
 	inspectResult.WithStack([]ast.Node{(*ast.CompositeLit)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		// the node is visited both on push and pop, only report it once
+		if !push {
+			return true
+		}
 		node := n.(*ast.CompositeLit)
 		// check if the node is a reflect header (slice or string) literal and report a warning if so
 		if compositeLiteralIsReflectHeader(node, pass) {
-			pass.Reportf(n.Pos(), "reflect header composite literal found")
+			pass.Report(analysis.Diagnostic{
+				Pos:            n.Pos(),
+				Message:        "reflect header composite literal found",
+				SuggestedFixes: compositeLiteralFixes(node, pass, stack),
+			})
 		}
 		return true
 	})
 
-	// filter AST of package under analysis for assignment statement nodes, which are the second possible
-	inspectResult.WithStack([]ast.Node{(*ast.AssignStmt)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
-		node := n.(*ast.AssignStmt)
+	// remember the headers that already got a fix suggested, because there is one fix per header but possibly multiple
+	// assignments to it
+	fixedHeaders := make(map[types.Object]bool)
+
+	// filter AST of package under analysis for assignment statement nodes, which are the second possible. This
+	// includes compound assignments like += as well as increment and decrement statements
+	nodeFilter := []ast.Node{(*ast.AssignStmt)(nil), (*ast.IncDecStmt)(nil)}
+	inspectResult.WithStack(nodeFilter, func(n ast.Node, push bool, stack []ast.Node) bool {
+		// the node is visited both on push and pop, only report it once
+		if !push {
+			return true
+		}
 		// check if the assignment is done to a reflect header that was derived incorrectly and report a warning if so
-		if assigningToReflectHeader(node, pass, stack, cfgResult) {
-			pass.Reportf(n.Pos(), "assigning to incorrectly derived reflect header object")
+		if assigningToReflectHeader(fieldWriteTargets(n), n, pass, stack, headers) {
+			pass.Report(analysis.Diagnostic{
+				Pos:            n.Pos(),
+				Message:        "assigning to incorrectly derived reflect header object",
+				SuggestedFixes: assignmentFixes(n, pass, stack, fixedHeaders),
+			})
 		}
 		return true
 	})
 
-	return nil, nil
-}
-
-/**
- * checks whether a type is a reflect header or similar type
- */
-func typeIsReflectHeader(t types.Type) bool {
-	// filter out possible parsing errors / invalid types
-	if t.String() == "invalid type" {
-		return false
-	}
-
-	// make up the basic structure of slice and string headers to compare to
-	sliceHeaderType := types.NewStruct([]*types.Var{
-		types.NewVar(token.NoPos, nil, "Data", types.Typ[types.Uintptr]),
-		types.NewVar(token.NoPos, nil, "Len", types.Typ[types.Int]),
-		types.NewVar(token.NoPos, nil, "Cap", types.Typ[types.Int]),
-	}, nil)
-	stringHeaderType := types.NewStruct([]*types.Var{
-		types.NewVar(token.NoPos, nil, "Data", types.Typ[types.Uintptr]),
-		types.NewVar(token.NoPos, nil, "Len", types.Typ[types.Int]),
-	}, nil)
-
-	// find the underlying type, taking care of a possible pointer type
-	var effectiveType types.Type
-	pt, ok := t.Underlying().(*types.Pointer)
-	if ok {
-		effectiveType = pt.Elem().Underlying()
-	} else {
-		effectiveType = t.Underlying()
-	}
+	// filter AST of package under analysis for call expressions, which pass headers to functions that assign to them
+	inspectResult.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		node := n.(*ast.CallExpr)
+		// check that every header argument written to by the callee is derived correctly, and report a warning if not
+		for _, arg := range headerArgumentsWritten(node, pass) {
+			if !argumentDerivedByCast(arg, node, headers, stack) {
+				pass.Reportf(arg.Pos(), "passing incorrectly derived reflect header object to a function that assigns to it")
+			}
+		}
+		return true
+	})
 
-	// check if the types are the same by checking whether they are assignable
-	return types.AssignableTo(sliceHeaderType, effectiveType) || types.AssignableTo(stringHeaderType, effectiveType)
+	return nil, nil
 }
 
 /**
@@ -97,9 +109,11 @@ func typeIsSliceOrStringReferenceType(t types.Type) bool {
 		return true
 	}
 
-	// check slice type by comparing the string representation of the type: it is a slice if it starts with *[]
-	if t.String()[0:3] == "*[]" {
-		return true
+	// check slice type by looking at the element type of the pointer: it is a slice if the pointer points to a slice
+	if pointer, ok := t.Underlying().(*types.Pointer); ok {
+		if _, ok := pointer.Elem().Underlying().(*types.Slice); ok {
+			return true
+		}
 	}
 
 	// otherwise, it must be something else
@@ -117,30 +131,35 @@ func compositeLiteralIsReflectHeader(cl *ast.CompositeLit, pass *analysis.Pass)
 	}
 
 	// check if the type is a reflect header
-	return typeIsReflectHeader(literalType.Type)
+	return resolve.IsReflectHeader(literalType.Type)
 }
 
 /**
- * checks if an assignment statement AST node is an assignment to a reflect header that is incorrectly derived
+ * returns the expressions that a statement assigns to, that is the left hand side of an assignment or the operand of
+ * an increment or decrement statement
  */
-func assigningToReflectHeader(assignStmt *ast.AssignStmt, pass *analysis.Pass, stack []ast.Node, cfgs *ctrlflow.CFGs) bool {
-	// find the function that contains the assignment statement by looking up the parsing stack
-	var function *ast.FuncDecl
-	for i := len(stack) - 1; i >= 0; i-- {
-		fDecl, ok := stack[i].(*ast.FuncDecl)
-		if ok {
-			function = fDecl
-			break
-		}
+func fieldWriteTargets(n ast.Node) []ast.Expr {
+	switch stmt := n.(type) {
+	case *ast.AssignStmt:
+		return stmt.Lhs
+	case *ast.IncDecStmt:
+		return []ast.Expr{stmt.X}
 	}
+	return nil
+}
 
+/**
+ * checks if an assignment statement AST node is an assignment to a reflect header that is incorrectly derived
+ */
+func assigningToReflectHeader(targets []ast.Expr, stmt ast.Node, pass *analysis.Pass, stack []ast.Node,
+	headers *headerDefinitions) bool {
 	// an assignment statement can have multiple assignments, therefore analyze all of them
-	for _, expr := range assignStmt.Lhs {
+	for _, expr := range targets {
 		// check if this assignment target is a selector expression, because only those can refer to fields in a
 		// reflect header struct type
 		lhs, ok := expr.(*ast.SelectorExpr)
 		if !ok {
-			return false
+			continue
 		}
 
 		// get the struct part of the assignment target and check that it is an identifier that we can analyze
@@ -148,7 +167,10 @@ func assigningToReflectHeader(assignStmt *ast.AssignStmt, pass *analysis.Pass, s
 		if !ok {
 			// if it isn't an identifier, get the type of it and check if it is a reflect header
 			lhsType := pass.TypesInfo.Types[lhs.X]
-			return typeIsReflectHeader(lhsType.Type)
+			if lhsType.Type != nil && resolve.IsReflectHeader(lhsType.Type) {
+				return true
+			}
+			continue
 		}
 
 		// if it is an identifier, we can now check whether it was derived safely. First, dereference the identifier
@@ -160,103 +182,18 @@ func assigningToReflectHeader(assignStmt *ast.AssignStmt, pass *analysis.Pass, s
 		}
 
 		// check if the object is a reflect header type
-		if typeIsReflectHeader(lhsObject.Type()) {
-			// now, find the path in the control flow graph that is used to construct the object
-			cfgStack := findPathInCFG(cfgs.FuncDecl(function), assignStmt)
-			// then check if it was derived by a safe cast from a real slice or string, and return true/false
-			// accordingly
-			if derivedByCast(lhsObject, cfgStack, pass) {
-				return false
+		if resolve.IsReflectHeader(lhsObject.Type()) {
+			// then check if every definition that may reach the assignment, in the enclosing function or closure, is
+			// a safe cast from a real slice or string
+			if !headers.derivedByCast(lhsObject, stmt, stack) {
+				return true
 			}
-			return true
 		}
 	}
 	// in the default case, it is not a reflect header target and therefore not warned
 	return false
 }
 
-/**
- * finds the path of nodes in a control flow graph up to a specified needle node
- */
-func findPathInCFG(cfg *cfg.CFG, stmt ast.Node) []ast.Node {
-	// use a recursive iterator function that keeps track of the depth, initialized to 0
-	_, stack := findPathInCFGIter(cfg.Blocks[0], &stmt, 0)
-	return stack
-}
-
-/**
- * iteratively finds a path from a starting CFG block to a node
- */
-func findPathInCFGIter(block *cfg.Block, stmt *ast.Node, depth int) (bool, []ast.Node) {
-	if depth > 1000 { // avoid stack exhaustion
-		return false, []ast.Node{}
-	}
-
-	// check if the node is contained in this CFG block, if so we can return true and the nodes
-	contained, nodes := nodesUntilStmt(&block.Nodes, stmt)
-	if contained {
-		return true, nodes
-	}
-
-	// otherwise, go through all children blocks and search the node there
-	for _, child := range block.Succs {
-		// search the node in this child, with incremented depth as a termination condition
-		found, childStack := findPathInCFGIter(child, stmt, depth+1)
-		if found {
-			// if the node is found somewhere in this child, prepend the nodes in the current block to all nodes
-			// on the path starting from the child, recursively returned
-			return true, append(block.Nodes, childStack...)
-		}
-	}
-
-	// otherwise, the node is not found
-	return false, []ast.Node{}
-}
-
-/**
- * returns the nods in a CFG block up to a specified needle node, if it is contained in the block
- */
-func nodesUntilStmt(nodes *[]ast.Node, stmt *ast.Node) (bool, []ast.Node) {
-	count := 0
-	// go through all nodes in the block
-	for _, n := range *nodes {
-		count++
-		// if it is the node we are searching for, return a slice of the nodes up to this point
-		if n == *stmt {
-			return true, (*nodes)[:count]
-		}
-	}
-	// if the node can't be found, return an empty nodes list
-	return false, []ast.Node{}
-}
-
-/**
- * checks if an object is derived with a cast from a real slice or string
- */
-func derivedByCast(object types.Object, cfgStack []ast.Node, pass *analysis.Pass) bool {
-	// we need to find the expression that defines the object under analysis
-	var definitionExpr ast.Expr
-	// go through the stack of nodes in the CFG, starting from the back because we are interested in the last assignment
-	// as this is defining the actual value of the object
-	for i := len(cfgStack) - 1; i >= 0; i-- {
-		node := cfgStack[i]
-		// check if this CFG node is an assignment to the object
-		assigns, valueExpr := nodeAssignsObject(node, object, pass)
-		if assigns {
-			definitionExpr = valueExpr
-			break
-		}
-	}
-
-	// if we cannot find an assignment to the object, we infer it was not derived safely by a cast
-	if definitionExpr == nil {
-		return false
-	}
-
-	// otherwise, check if the defining statement is a cast from a real slice or string
-	return definitionExprIsCastFromRealSlice(definitionExpr, pass)
-}
-
 /**
  * checks if an AST node is an assignment of a given object
  */
@@ -312,7 +249,7 @@ func definitionExprIsCastFromRealSlice(expr ast.Expr, pass *analysis.Pass) bool
 	}
 
 	// check that the cast target is a reflect header structure
-	if !definitionCastPointerTargetIsReflectHeader(callExpr) {
+	if !definitionCastPointerTargetIsReflectHeader(callExpr, pass) || len(callExpr.Args) != 1 {
 		return false
 	}
 
@@ -323,20 +260,9 @@ func definitionExprIsCastFromRealSlice(expr ast.Expr, pass *analysis.Pass) bool
 		return false
 	}
 
-	// check that the callee is a selector expression (unsafe.Pointer is)
-	sourceSelector, ok := sourceCast.Fun.(*ast.SelectorExpr)
-	if !ok {
-		return false
-	}
-
-	// convert the selector receiver to an identifier
-	sourceReceiver, ok := sourceSelector.X.(*ast.Ident)
-	if !ok {
-		return false
-	}
-
-	// check that the intermediate cast is indeed to unsafe.Pointer
-	if sourceSelector.Sel.Name != "Pointer" || sourceReceiver.Name != "unsafe" {
+	// check that the intermediate cast is indeed to unsafe.Pointer, using the type of the callee rather than its name
+	// so that renamed or shadowed imports are handled correctly
+	if !resolve.IsUnsafePointerConversion(pass.TypesInfo, sourceCast) {
 		return false
 	}
 
@@ -350,7 +276,10 @@ func definitionExprIsCastFromRealSlice(expr ast.Expr, pass *analysis.Pass) bool
 	return typeIsSliceOrStringReferenceType(sourceType.Type)
 }
 
-func definitionCastPointerTargetIsReflectHeader(callExpr *ast.CallExpr) bool {
+/**
+ * checks whether a call expression is a cast to a pointer to reflect.SliceHeader or reflect.StringHeader
+ */
+func definitionCastPointerTargetIsReflectHeader(callExpr *ast.CallExpr, pass *analysis.Pass) bool {
 	var ok bool
 
 	// check that the cast callee is a parenthesis expression
@@ -366,28 +295,9 @@ func definitionCastPointerTargetIsReflectHeader(callExpr *ast.CallExpr) bool {
 		return false
 	}
 
-	// the star must be in front of a selector expression (e.g. reflect.SliceHeader)
-	castPointerTarget, ok := castStarTarget.X.(*ast.SelectorExpr)
-	if !ok {
-		return false
-	}
-
-	// first, check if the selector is either that of a slice or string header
-	if castPointerTarget.Sel.Name != "SliceHeader" && castPointerTarget.Sel.Name != "StringHeader" {
-		return false
-	}
-
-	// if so, convert the selector receiver to an identifier
-	selectorReceiver, ok := castPointerTarget.X.(*ast.Ident)
-	if !ok {
-		return false
-	}
-
-	// and check if it is indeed reflect
-	if selectorReceiver.Name != "reflect" {
-		return false
-	}
-
-	// if all conditions hold, return true
-	return true
+	// finally, check that the star is in front of the slice or string header type of the reflect package. This resolves
+	// the type through the type information, so renamed and dot imports of reflect work as well
+	info := pass.TypesInfo
+	return resolve.IsPackageObject(info, castStarTarget.X, "reflect", "SliceHeader") ||
+		resolve.IsPackageObject(info, castStarTarget.X, "reflect", "StringHeader")
 }
@@ -3,6 +3,7 @@ package sliceheader_test
 import (
 	"github.com/jlauinger/go-safer/passes/sliceheader"
 	"golang.org/x/tools/go/analysis/analysistest"
+	"path/filepath"
 	"testing"
 )
 
@@ -17,11 +18,38 @@ func Test(t *testing.T) {
 		"bad/variable_declaration",
 		"bad/unsafe_cast",
 		"bad/nil_cast",
+		"bad/new_header",
+		"bad/branch_nil",
+		"bad/closure",
+		"bad/package_level",
+		"bad/header_parameter",
+		"bad/increment_decrement",
+		"bad/multi_assign",
+		"bad/shadowed_unsafe",
+		"bad/used_variable",
+		"bad/generic_helper",
 
 		"good/safe_cast",
 		"good/safe_cast_dereferenced_header",
 		"good/unrelated_selector",
+		"good/branch_safe",
+		"good/closure",
+		"good/package_level",
+		"good/header_parameter_lib",
+		"good/header_parameter",
+		"good/sliding_window",
+		"good/renamed_import",
 	}
-	analysistest.Run(t, testdata, sliceheader.Analyzer, testPackages...)
+	analysistest.RunWithSuggestedFixes(t, testdata, sliceheader.Analyzer, testPackages...)
 }
 
+func TestModule(t *testing.T) {
+	// code examples that need a go directive, e.g. for fixes to unsafe.Slice, live in a module instead of GOPATH
+	testdata := filepath.Join(analysistest.TestData(), "mod")
+	testPackages := []string{
+		"./string_to_bytes",
+		"./bytes_to_string",
+		"./foreign_data",
+	}
+	analysistest.RunWithSuggestedFixes(t, testdata, sliceheader.Analyzer, testPackages...)
+}
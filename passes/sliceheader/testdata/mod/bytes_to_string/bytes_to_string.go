@@ -0,0 +1,15 @@
+package bytes_to_string
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+func UnsafeCastBytes(b []byte) string {
+	sliceH := *(*reflect.SliceHeader)(unsafe.Pointer(&b))
+	strH := reflect.StringHeader{ // want "reflect header composite literal found"
+		Data: sliceH.Data,
+		Len:  sliceH.Len,
+	}
+	return *(*string)(unsafe.Pointer(&strH))
+}
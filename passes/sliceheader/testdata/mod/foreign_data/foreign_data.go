@@ -0,0 +1,17 @@
+package foreign_data
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+func UnsafeCastPointer(data uintptr, length int) []byte {
+	b := 42
+	sH := &reflect.SliceHeader{ // want "reflect header composite literal found"
+		Data: data,
+		Len:  length,
+		Cap:  length,
+	}
+	_ = b
+	return *(*[]byte)(unsafe.Pointer(sH))
+}
@@ -0,0 +1,16 @@
+package string_to_bytes
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+func UnsafeCastString(str string) []byte {
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+	sH := &reflect.SliceHeader{ // want "reflect header composite literal found"
+		Data: strH.Data,
+		Cap:  strH.Len,
+		Len:  strH.Len,
+	}
+	return *(*[]byte)(unsafe.Pointer(sH))
+}
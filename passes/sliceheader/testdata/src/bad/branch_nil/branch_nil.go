@@ -0,0 +1,35 @@
+package branch_nil
+
+import (
+	"reflect"
+	"runtime"
+	"unsafe"
+)
+
+func MaybeSafeCastString(str string, safe bool) (b []byte) {
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+	var sH *reflect.SliceHeader
+	if safe {
+		sH = (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	} else {
+		sH = (*reflect.SliceHeader)(nil)
+	}
+	sH.Len = strH.Len // want "assigning to incorrectly derived reflect header object"
+	sH.Cap = strH.Len // want "assigning to incorrectly derived reflect header object"
+	sH.Data = strH.Data // want "assigning to incorrectly derived reflect header object"
+	runtime.KeepAlive(str)
+	return
+}
+
+func ReassignedInLoop(strs []string) (b []byte) {
+	sH := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	for _, str := range strs {
+		strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+		sH.Len = strH.Len // want "assigning to incorrectly derived reflect header object"
+		sH.Cap = strH.Len // want "assigning to incorrectly derived reflect header object"
+		sH.Data = strH.Data // want "assigning to incorrectly derived reflect header object"
+		runtime.KeepAlive(str)
+		sH = new(reflect.SliceHeader)
+	}
+	return
+}
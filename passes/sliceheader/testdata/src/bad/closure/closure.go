@@ -0,0 +1,32 @@
+package closure
+
+import (
+	"reflect"
+	"runtime"
+	"unsafe"
+)
+
+func GoroutineCastString(str string, result chan []byte) {
+	go func() {
+		var b []byte
+		strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+		sH := (*reflect.SliceHeader)(nil)
+		sH.Len = strH.Len // want "assigning to incorrectly derived reflect header object"
+		sH.Cap = strH.Len // want "assigning to incorrectly derived reflect header object"
+		sH.Data = strH.Data // want "assigning to incorrectly derived reflect header object"
+		runtime.KeepAlive(str)
+		result <- b
+	}()
+}
+
+func DeferredCastString(str string) (b []byte) {
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+	sH := (*reflect.SliceHeader)(unsafe.Pointer(nil))
+	defer func() {
+		sH.Len = strH.Len // want "assigning to incorrectly derived reflect header object"
+		sH.Cap = strH.Len // want "assigning to incorrectly derived reflect header object"
+		sH.Data = strH.Data // want "assigning to incorrectly derived reflect header object"
+		runtime.KeepAlive(str)
+	}()
+	return
+}
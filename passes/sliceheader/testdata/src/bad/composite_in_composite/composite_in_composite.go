@@ -12,7 +12,7 @@ type Foo struct {
 func UnsafeCastString(str string) []byte {
 	strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
 	foo := Foo {
-		Bar: &reflect.SliceHeader{ // want "reflect header composite literal found" "reflect header composite literal found"
+		Bar: &reflect.SliceHeader{ // want "reflect header composite literal found"
 			Data: strH.Data,
 			Cap: strH.Len,
 			Len: strH.Len,
@@ -0,0 +1,18 @@
+package generic_helper
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+func HeaderOf[T any](p *T) *reflect.SliceHeader {
+	return (*reflect.SliceHeader)(unsafe.Pointer(p))
+}
+
+func CastLength(n int) []byte {
+	var x [4]int64
+	sH := HeaderOf(&x)
+	sH.Len = n // want "assigning to incorrectly derived reflect header object"
+	sH.Cap = n // want "assigning to incorrectly derived reflect header object"
+	return *(*[]byte)(unsafe.Pointer(sH))
+}
@@ -0,0 +1,37 @@
+package header_parameter
+
+import (
+	"good/header_parameter_lib"
+	"reflect"
+	"unsafe"
+)
+
+func CastStringNil(s string) []byte {
+	sH := (*reflect.SliceHeader)(nil)
+	header_parameter_lib.Fill(sH, s) // want "passing incorrectly derived reflect header object to a function that assigns to it"
+	return *(*[]byte)(unsafe.Pointer(sH))
+}
+
+func CastStringForwarded(s string) []byte {
+	sH := new(reflect.SliceHeader)
+	header_parameter_lib.FillForwarded(s, sH) // want "passing incorrectly derived reflect header object to a function that assigns to it"
+	return *(*[]byte)(unsafe.Pointer(sH))
+}
+
+func fillLength(h *reflect.SliceHeader, n int) { // want fillLength:"writesHeaderParams\\[0\\]"
+	h.Len = n // ok
+	h.Cap = n // ok
+}
+
+func fillLengthLater(h *reflect.SliceHeader, n int) func() { // want fillLengthLater:"writesHeaderParams\\[0\\]"
+	return func() {
+		fillLength(h, n) // ok
+	}
+}
+
+func CastLength(n int) []byte {
+	var sH *reflect.SliceHeader
+	fillLength(sH, n) // want "passing incorrectly derived reflect header object to a function that assigns to it"
+	fillLengthLater(sH, n)() // want "passing incorrectly derived reflect header object to a function that assigns to it"
+	return *(*[]byte)(unsafe.Pointer(sH))
+}
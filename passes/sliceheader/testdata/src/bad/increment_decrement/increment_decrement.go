@@ -0,0 +1,16 @@
+package increment_decrement
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+func SlideWindow(buf []byte, offset int) []byte {
+	bufH := (*reflect.SliceHeader)(unsafe.Pointer(&buf))
+	var sH *reflect.SliceHeader
+	sH.Data = bufH.Data // want "assigning to incorrectly derived reflect header object"
+	sH.Data += uintptr(offset) // want "assigning to incorrectly derived reflect header object"
+	sH.Len++ // want "assigning to incorrectly derived reflect header object"
+	sH.Cap-- // want "assigning to incorrectly derived reflect header object"
+	return *(*[]byte)(unsafe.Pointer(sH))
+}
@@ -0,0 +1,17 @@
+package multi_assign
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+func UnsafeCastString(str string) []byte {
+	var n int
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+	sH := (*reflect.SliceHeader)(nil)
+	n, sH.Len = strH.Len, strH.Len // want "assigning to incorrectly derived reflect header object"
+	sH.Cap, n = strH.Len, n // want "assigning to incorrectly derived reflect header object"
+	n, sH.Data = 0, strH.Data // want "assigning to incorrectly derived reflect header object"
+	_ = n
+	return *(*[]byte)(unsafe.Pointer(sH))
+}
@@ -0,0 +1,12 @@
+package new_header
+
+import "reflect"
+import "unsafe"
+
+func UnsafeCastBytes(b []byte) string {
+	sliceH := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	strH := new(reflect.StringHeader)
+	strH.Data = sliceH.Data // want "assigning to incorrectly derived reflect header object"
+	strH.Len = sliceH.Len // want "assigning to incorrectly derived reflect header object"
+	return *(*string)(unsafe.Pointer(strH))
+}
@@ -0,0 +1,26 @@
+package package_level
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+var header = (*reflect.SliceHeader)(unsafe.Pointer(nil))
+
+var source = "Hello World"
+
+func init() {
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&source))
+	header.Len = strH.Len // want "assigning to incorrectly derived reflect header object"
+	header.Cap = strH.Len // want "assigning to incorrectly derived reflect header object"
+	header.Data = strH.Data // want "assigning to incorrectly derived reflect header object"
+}
+
+var initialized = func() []byte {
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&source))
+	var sH *reflect.SliceHeader
+	sH.Len = strH.Len // want "assigning to incorrectly derived reflect header object"
+	sH.Cap = strH.Len // want "assigning to incorrectly derived reflect header object"
+	sH.Data = strH.Data // want "assigning to incorrectly derived reflect header object"
+	return *(*[]byte)(unsafe.Pointer(sH))
+}()
@@ -0,0 +1,16 @@
+package shadowed_unsafe
+
+import (
+	"reflect"
+
+	"bad/shadowed_unsafe/unsafe"
+)
+
+func ShadowedUnsafe(str string) (b []byte) {
+	strH := &reflect.StringHeader{Data: 0, Len: len(str)} // want "reflect header composite literal found"
+	sH := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	sH.Len = strH.Len // want "assigning to incorrectly derived reflect header object"
+	sH.Cap = strH.Len // want "assigning to incorrectly derived reflect header object"
+	sH.Data = strH.Data // want "assigning to incorrectly derived reflect header object"
+	return
+}
@@ -0,0 +1,8 @@
+package unsafe
+
+import "reflect"
+
+// Pointer has the same name as unsafe.Pointer, but it returns a new header that is not derived from its argument
+func Pointer(p interface{}) *reflect.SliceHeader {
+	return &reflect.SliceHeader{} // want "reflect header composite literal found"
+}
@@ -0,0 +1,42 @@
+package used_variable
+
+import (
+	"reflect"
+	"runtime"
+	"unsafe"
+)
+
+func ReturnedCastString(str string) []byte {
+	var b []byte
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+	sH := (*reflect.SliceHeader)(nil)
+	sH.Len = strH.Len // want "assigning to incorrectly derived reflect header object"
+	sH.Cap = strH.Len // want "assigning to incorrectly derived reflect header object"
+	sH.Data = strH.Data // want "assigning to incorrectly derived reflect header object"
+	runtime.KeepAlive(str)
+	return b
+}
+
+func ReturnedEither(str string, first bool) []byte {
+	var b, c []byte
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+	sH := (*reflect.SliceHeader)(nil)
+	sH.Len = strH.Len // want "assigning to incorrectly derived reflect header object"
+	sH.Cap = strH.Len // want "assigning to incorrectly derived reflect header object"
+	sH.Data = strH.Data // want "assigning to incorrectly derived reflect header object"
+	runtime.KeepAlive(str)
+	if first {
+		return b
+	}
+	return c
+}
+
+func ReturnedSource(src []byte) []byte {
+	srcH := (*reflect.SliceHeader)(unsafe.Pointer(&src))
+	sH := (*reflect.SliceHeader)(nil)
+	sH.Len = srcH.Len // want "assigning to incorrectly derived reflect header object"
+	sH.Cap = srcH.Len // want "assigning to incorrectly derived reflect header object"
+	sH.Data = srcH.Data // want "assigning to incorrectly derived reflect header object"
+	runtime.KeepAlive(src)
+	return src
+}
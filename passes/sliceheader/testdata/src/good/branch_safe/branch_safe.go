@@ -0,0 +1,35 @@
+package branch_safe
+
+import (
+	"reflect"
+	"runtime"
+	"unsafe"
+)
+
+func CastEitherString(a, b string, first bool) (s []byte) {
+	var t []byte
+	var sH *reflect.SliceHeader
+	if first {
+		sH = (*reflect.SliceHeader)(unsafe.Pointer(&s))
+	} else {
+		sH = (*reflect.SliceHeader)(unsafe.Pointer(&t))
+	}
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&a))
+	sH.Len = strH.Len // ok
+	sH.Cap = strH.Len // ok
+	sH.Data = strH.Data // ok
+	runtime.KeepAlive(a)
+	_ = b
+	return
+}
+
+func OverwrittenBeforeUse(str string) (b []byte) {
+	sH := (*reflect.SliceHeader)(nil)
+	sH = (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+	sH.Len = strH.Len // ok
+	sH.Cap = strH.Len // ok
+	sH.Data = strH.Data // ok
+	runtime.KeepAlive(str)
+	return
+}
@@ -0,0 +1,32 @@
+package closure
+
+import (
+	"reflect"
+	"runtime"
+	"unsafe"
+)
+
+func GoroutineCastString(str string, result chan []byte) {
+	go func() {
+		var b []byte
+		strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+		sH := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+		sH.Len = strH.Len // ok
+		sH.Cap = strH.Len // ok
+		sH.Data = strH.Data // ok
+		runtime.KeepAlive(str)
+		result <- b
+	}()
+}
+
+func DeferredCastString(str string) (b []byte) {
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&str))
+	sH := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	defer func() {
+		sH.Len = strH.Len // ok
+		sH.Cap = strH.Len // ok
+		sH.Data = strH.Data // ok
+		runtime.KeepAlive(str)
+	}()
+	return
+}
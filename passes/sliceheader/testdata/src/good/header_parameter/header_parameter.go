@@ -0,0 +1,31 @@
+package header_parameter
+
+import (
+	"good/header_parameter_lib"
+	"reflect"
+	"runtime"
+	"unsafe"
+)
+
+func CastString(s string) (b []byte) {
+	sH := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	header_parameter_lib.Fill(sH, s) // ok
+	runtime.KeepAlive(s)
+	return
+}
+
+func CastStringForwarded(s string) (b []byte) {
+	header_parameter_lib.FillForwarded(s, (*reflect.SliceHeader)(unsafe.Pointer(&b))) // ok
+	runtime.KeepAlive(s)
+	return
+}
+
+func CastStringWithHelper(s string) (b []byte) {
+	sH := header_parameter_lib.HeaderOf(&b)
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&s))
+	sH.Len = strH.Len // ok
+	sH.Cap = strH.Len // ok
+	sH.Data = strH.Data // ok
+	runtime.KeepAlive(s)
+	return
+}
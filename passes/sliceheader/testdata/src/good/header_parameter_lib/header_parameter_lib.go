@@ -0,0 +1,21 @@
+package header_parameter_lib
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+func Fill(h *reflect.SliceHeader, s string) { // want Fill:"writesHeaderParams\\[0\\]"
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&s))
+	h.Len = strH.Len // ok
+	h.Cap = strH.Len // ok
+	h.Data = strH.Data // ok
+}
+
+func FillForwarded(s string, h *reflect.SliceHeader) { // want FillForwarded:"writesHeaderParams\\[1\\]"
+	Fill(h, s) // ok
+}
+
+func HeaderOf(b *[]byte) *reflect.SliceHeader { // want HeaderOf:"returnsSafeHeader"
+	return (*reflect.SliceHeader)(unsafe.Pointer(b))
+}
@@ -0,0 +1,28 @@
+package package_level
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+var buffer []byte
+
+var header = (*reflect.SliceHeader)(unsafe.Pointer(&buffer))
+
+var source = "Hello World"
+
+func init() {
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&source))
+	header.Len = strH.Len // ok
+	header.Cap = strH.Len // ok
+	header.Data = strH.Data // ok
+}
+
+var initialized = func() (b []byte) {
+	strH := (*reflect.StringHeader)(unsafe.Pointer(&source))
+	sH := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	sH.Len = strH.Len // ok
+	sH.Cap = strH.Len // ok
+	sH.Data = strH.Data // ok
+	return
+}()
@@ -0,0 +1,17 @@
+package renamed_import
+
+import (
+	"reflect"
+	"runtime"
+	. "unsafe"
+)
+
+func DotImports(str string) (b []byte) {
+	strH := (*reflect.StringHeader)(Pointer(&str))
+	sH := (*reflect.SliceHeader)(Pointer(&b))
+	sH.Len = strH.Len // ok
+	sH.Cap = strH.Len // ok
+	sH.Data = strH.Data // ok
+	runtime.KeepAlive(str)
+	return
+}
@@ -0,0 +1,17 @@
+package renamed_import
+
+import (
+	r "reflect"
+	"runtime"
+	u "unsafe"
+)
+
+func RenamedImports(str string) (b []byte) {
+	strH := (*r.StringHeader)(u.Pointer(&str))
+	sH := (*r.SliceHeader)(u.Pointer(&b))
+	sH.Len = strH.Len // ok
+	sH.Cap = strH.Len // ok
+	sH.Data = strH.Data // ok
+	runtime.KeepAlive(str)
+	return
+}
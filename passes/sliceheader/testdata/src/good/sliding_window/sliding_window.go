@@ -0,0 +1,26 @@
+package sliding_window
+
+import (
+	"reflect"
+	"runtime"
+	"unsafe"
+)
+
+func SlideWindow(buf []byte, offset int) (window []byte) {
+	bufH := (*reflect.SliceHeader)(unsafe.Pointer(&buf))
+	sH := (*reflect.SliceHeader)(unsafe.Pointer(&window))
+	sH.Data = bufH.Data // ok
+	sH.Len = bufH.Len // ok
+	sH.Cap = bufH.Cap // ok
+	runtime.KeepAlive(buf)
+	for i := 0; i < offset; i++ {
+		sH.Data++ // ok
+		sH.Len-- // ok
+		sH.Cap-- // ok
+	}
+	sH.Data += 0 // ok
+	var n int
+	n, sH.Len = 0, sH.Len // ok
+	_ = n
+	return
+}
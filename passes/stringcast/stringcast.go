@@ -0,0 +1,120 @@
+package stringcast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+)
+
+// Analyzer is a golang.org/x/tools/go/analysis style linter pass.
+// Use this with the Vet-style infrastructure.
+var Analyzer = &analysis.Analyzer{
+	Name:             "stringcast",
+	Doc:              "reports direct casts between string and slice headers through unsafe.Pointer",
+	Run:              run,
+	Requires:         []*analysis.Analyzer{inspect.Analyzer},
+	RunDespiteErrors: true,
+}
+
+/**
+ * run is the entry point to the analysis pass
+ */
+func run(pass *analysis.Pass) (interface{}, error) {
+	// get results from required inspect analyzer
+	inspectResult := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	// filter AST of package under analysis for CallExpr nodes
+	inspectResult.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		node := n.(*ast.CallExpr)
+
+		// check if the node is a cast between a string and a slice through unsafe.Pointer
+		src, dst, ok := detectStringSliceCast(node, pass)
+		if !ok {
+			return
+		}
+
+		// report a warning depending on the direction of the cast. Casting a string to a slice is never safe, while
+		// casting a slice to a string is fine as long as the slice is treated as read-only afterwards
+		qualifier := types.RelativeTo(pass.Pkg)
+		if isString(src) {
+			pass.Report(analysis.Diagnostic{
+				Pos:      n.Pos(),
+				Category: "string-to-slice",
+				Message: fmt.Sprintf("unsafe cast from *%s to *%s reads the slice capacity from memory after the "+
+					"string header and makes immutable string data writable", types.TypeString(src, qualifier),
+					types.TypeString(dst, qualifier)),
+			})
+		} else {
+			pass.Report(analysis.Diagnostic{
+				Pos:      n.Pos(),
+				Category: "slice-to-string",
+				Message: fmt.Sprintf("unsafe cast from *%s to *%s is only safe if the slice is never modified "+
+					"afterwards", types.TypeString(src, qualifier), types.TypeString(dst, qualifier)),
+			})
+		}
+	})
+
+	return nil, nil
+}
+
+/**
+ * checks if a CallExpr node is a cast of the form (*T)(unsafe.Pointer(p)), where one of T and the type that p points
+ * to is a string and the other one is a slice. Returns the source and destination types if so
+ */
+func detectStringSliceCast(call *ast.CallExpr, pass *analysis.Pass) (types.Type, types.Type, bool) {
+	// check that the call is a conversion to a pointer type
+	if len(call.Args) != 1 {
+		return nil, nil, false
+	}
+	target, ok := pass.TypesInfo.Types[call.Fun]
+	if !ok || !target.IsType() {
+		return nil, nil, false
+	}
+	dstPointer, ok := target.Type.Underlying().(*types.Pointer)
+	if !ok {
+		return nil, nil, false
+	}
+
+	// check that the argument is a direct conversion of another pointer to unsafe.Pointer
+	sourceCast, ok := ast.Unparen(call.Args[0]).(*ast.CallExpr)
+	if !ok || !resolve.IsUnsafePointerConversion(pass.TypesInfo, sourceCast) {
+		return nil, nil, false
+	}
+	srcType := pass.TypesInfo.TypeOf(sourceCast.Args[0])
+	if srcType == nil {
+		return nil, nil, false
+	}
+	srcPointer, ok := srcType.Underlying().(*types.Pointer)
+	if !ok {
+		return nil, nil, false
+	}
+
+	// finally, check that one side is a string and the other side is a slice
+	src, dst := srcPointer.Elem(), dstPointer.Elem()
+	if (isString(src) && isSlice(dst)) || (isSlice(src) && isString(dst)) {
+		return src, dst, true
+	}
+	return nil, nil, false
+}
+
+/**
+ * checks whether a type is a string type
+ */
+func isString(t types.Type) bool {
+	basic, ok := t.Underlying().(*types.Basic)
+	return ok && basic.Kind() == types.String
+}
+
+/**
+ * checks whether a type is a slice type
+ */
+func isSlice(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Slice)
+	return ok
+}
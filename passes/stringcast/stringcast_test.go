@@ -0,0 +1,20 @@
+package stringcast_test
+
+import (
+	"github.com/jlauinger/go-safer/passes/stringcast"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"testing"
+)
+
+func Test(t *testing.T) {
+	// use go vet infrastructure testing and supply annotated code examples
+	testdata := analysistest.TestData()
+	testPackages := []string{
+		"bad/string_to_bytes",
+		"bad/bytes_to_string",
+
+		"good/safe_conversion",
+		"good/type_error",
+	}
+	analysistest.Run(t, testdata, stringcast.Analyzer, testPackages...)
+}
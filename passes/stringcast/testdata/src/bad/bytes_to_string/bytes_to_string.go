@@ -0,0 +1,7 @@
+package bytes_to_string
+
+import "unsafe"
+
+func BytesToString(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b)) // want "unsafe cast from \\*\\[\\]byte to \\*string is only safe if the slice is never modified afterwards"
+}
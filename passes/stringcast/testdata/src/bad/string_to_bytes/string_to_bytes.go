@@ -0,0 +1,18 @@
+package string_to_bytes
+
+import "unsafe"
+
+type Text string
+
+func StringToBytes(s string) []byte {
+	return *(*[]byte)(unsafe.Pointer(&s)) // want "unsafe cast from \\*string to \\*\\[\\]byte reads the slice capacity from memory after the string header and makes immutable string data writable"
+}
+
+func NamedStringToBytes(t Text) []byte {
+	p := (*[]byte)(unsafe.Pointer(&t)) // want "unsafe cast from \\*Text to \\*\\[\\]byte reads the slice capacity from memory after the string header and makes immutable string data writable"
+	return *p
+}
+
+func StringToRunes(s string) []rune {
+	return *(*[]rune)(unsafe.Pointer(&s)) // want "unsafe cast from \\*string to \\*\\[\\]rune reads the slice capacity from memory after the string header and makes immutable string data writable"
+}
@@ -0,0 +1,19 @@
+package safe_conversion
+
+import "unsafe"
+
+func StringToBytes(s string) []byte {
+	return unsafe.Slice(unsafe.StringData(s), len(s)) // ok
+}
+
+func BytesToString(b []byte) string {
+	return unsafe.String(unsafe.SliceData(b), len(b)) // ok
+}
+
+func CopyingConversion(s string) []byte {
+	return []byte(s) // ok
+}
+
+func SameType(b []byte) []byte {
+	return *(*[]byte)(unsafe.Pointer(&b)) // ok
+}
@@ -0,0 +1,8 @@
+package type_error
+
+import "unsafe"
+
+func UndefinedSource() []byte {
+	// the source has no type, because it is not declared
+	return *(*[]byte)(unsafe.Pointer(&undefined))
+}
@@ -0,0 +1,102 @@
+package stringmutation
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// mutatesParams is exported for functions that modify the contents of slices passed as parameters, either directly or
+// by passing them on to other functions that do. Params holds the parameter indices
+type mutatesParams struct {
+	Params []int
+}
+
+func (*mutatesParams) AFact() {}
+
+func (f *mutatesParams) String() string {
+	return fmt.Sprintf("mutatesParams%v", f.Params)
+}
+
+// returnsStringBytes is exported for functions that return a byte slice sharing memory with a string. SpareCapacity is
+// true if the capacity of the slice may be larger than its length, so that append writes into the string data
+type returnsStringBytes struct {
+	SpareCapacity bool
+}
+
+func (*returnsStringBytes) AFact() {}
+
+func (f *returnsStringBytes) String() string {
+	if f.SpareCapacity {
+		return "returnsStringBytes with spare capacity"
+	}
+	return "returnsStringBytes"
+}
+
+// knownMutators lists functions outside of the analyzed packages that write into slices passed to them, with the
+// indices of the arguments that they write to
+var knownMutators = map[string][]int{
+	"io.ReadFull":                              {1},
+	"io.ReadAtLeast":                           {1},
+	"(io.Reader).Read":                         {0},
+	"(io.ReaderAt).ReadAt":                     {0},
+	"crypto/rand.Read":                         {0},
+	"math/rand.Read":                           {0},
+	"encoding/hex.Decode":                      {0},
+	"encoding/hex.Encode":                      {0},
+	"(*encoding/base64.Encoding).Decode":       {0},
+	"(*encoding/base64.Encoding).Encode":       {0},
+	"(encoding/binary.bigEndian).PutUint16":    {0},
+	"(encoding/binary.bigEndian).PutUint32":    {0},
+	"(encoding/binary.bigEndian).PutUint64":    {0},
+	"(encoding/binary.littleEndian).PutUint16": {0},
+	"(encoding/binary.littleEndian).PutUint32": {0},
+	"(encoding/binary.littleEndian).PutUint64": {0},
+	"encoding/binary.PutUvarint":               {0},
+	"encoding/binary.PutVarint":                {0},
+	"unicode/utf8.EncodeRune":                  {0},
+	"slices.Reverse":                           {0},
+	"slices.Sort":                              {0},
+}
+
+/**
+ * returns the indices of the arguments of a call that the callee writes into, and the callee itself
+ */
+func mutatedArguments(call *ast.CallExpr, pass *analysis.Pass) ([]int, *types.Func) {
+	callee, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok {
+		return nil, nil
+	}
+	fact := new(mutatesParams)
+	if pass.ImportObjectFact(callee, fact) {
+		return fact.Params, callee
+	}
+	if params, ok := knownMutators[callee.Origin().FullName()]; ok {
+		return params, callee
+	}
+	return nil, nil
+}
+
+/**
+ * exports the parameters that a function mutates, if they are not known yet. Returns true if the fact changed
+ */
+func exportMutatedParams(function *types.Func, params map[int]bool, pass *analysis.Pass) bool {
+	fact := &mutatesParams{}
+	for param := range params {
+		fact.Params = append(fact.Params, param)
+	}
+	if len(fact.Params) == 0 {
+		return false
+	}
+	sort.Ints(fact.Params)
+	previous := new(mutatesParams)
+	if pass.ImportObjectFact(function, previous) && len(previous.Params) == len(fact.Params) {
+		return false
+	}
+	pass.ExportObjectFact(function, fact)
+	return true
+}
@@ -0,0 +1,158 @@
+package stringmutation
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+/**
+ * finds the value that a variable holds at the node at the end of a stack, by looking for the closest assignment to it
+ * in the statements that run right before. It returns the assigned value and the stack of the assignment, where a nil
+ * value means the variable was assigned something that is not tracked. The last return value is false if the value
+ * depends on branches, loops, closures or pointers, which are not followed
+ */
+func precedingValue(object types.Object, stack []ast.Node, info *types.Info) (ast.Expr, []ast.Node, bool) {
+	for i := len(stack) - 1; i > 0; i-- {
+		var statements []ast.Stmt
+		switch parent := stack[i-1].(type) {
+		case *ast.BlockStmt:
+			statements = parent.List
+		case *ast.CaseClause:
+			statements = parent.Body
+		case *ast.CommClause:
+			// the communication runs before the body, like v = <-c
+			if parent.Comm != nil && assigns(parent.Comm, object, info) {
+				return nil, nil, false
+			}
+			statements = parent.Body
+		case *ast.ForStmt, *ast.RangeStmt:
+			// a loop might assign the variable in a previous iteration
+			if assigns(parent, object, info) {
+				return nil, nil, false
+			}
+			continue
+		case *ast.IfStmt:
+			// init statements run before the branches, but are not part of a block
+			if parent.Init != nil && assigns(parent.Init, object, info) {
+				return nil, nil, false
+			}
+			continue
+		case *ast.SwitchStmt:
+			if parent.Init != nil && assigns(parent.Init, object, info) {
+				return nil, nil, false
+			}
+			continue
+		case *ast.TypeSwitchStmt:
+			if parent.Init != nil && assigns(parent.Init, object, info) {
+				return nil, nil, false
+			}
+			continue
+		case *ast.FuncLit, *ast.FuncDecl:
+			return nil, nil, false
+		default:
+			continue
+		}
+
+		// go through the statements of the block before the one containing the node, from the closest one
+		current := indexOf(statements, stack[i])
+		for j := current - 1; j >= 0; j-- {
+			value, assigned, ok := assignedValue(statements[j], object, info)
+			if !ok {
+				return nil, nil, false
+			}
+			if assigned {
+				return value, append(stack[:i:i], statements[j]), true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+/**
+ * checks whether a statement assigns a value to a variable. The value is nil if the statement does not assign it as
+ * a single expression. The last return value is false if the statement might assign the variable somewhere nested
+ */
+func assignedValue(statement ast.Stmt, object types.Object, info *types.Info) (ast.Expr, bool, bool) {
+	switch stmt := statement.(type) {
+	case *ast.AssignStmt:
+		if stmt.Tok != token.ASSIGN && stmt.Tok != token.DEFINE {
+			break
+		}
+		for i, lhs := range stmt.Lhs {
+			if !isObject(lhs, object, info) {
+				continue
+			}
+			if len(stmt.Lhs) != len(stmt.Rhs) {
+				return nil, true, !assigns(stmt, object, info, stmt.Lhs[i])
+			}
+			return stmt.Rhs[i], true, !assigns(stmt.Rhs[i], object, info)
+		}
+	case *ast.DeclStmt:
+		if decl, ok := stmt.Decl.(*ast.GenDecl); ok && decl.Tok == token.VAR {
+			for _, spec := range decl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				for i, name := range valueSpec.Names {
+					if info.Defs[name] != object {
+						continue
+					}
+					if len(valueSpec.Names) != len(valueSpec.Values) {
+						return nil, true, true
+					}
+					return valueSpec.Values[i], true, true
+				}
+			}
+		}
+	}
+	return nil, false, !assigns(statement, object, info)
+}
+
+/**
+ * checks whether a node assigns a variable anywhere within it, or takes its address so that it might be assigned
+ * through a pointer. Occurrences in the given expressions are ignored
+ */
+func assigns(node ast.Node, object types.Object, info *types.Info, ignored ...ast.Expr) bool {
+	found := false
+	isAssigned := func(expr ast.Expr) bool {
+		for _, ignore := range ignored {
+			if expr == ignore {
+				return false
+			}
+		}
+		return isObject(expr, object, info)
+	}
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				found = found || isAssigned(lhs)
+			}
+		case *ast.RangeStmt:
+			found = found || (n.Key != nil && isAssigned(n.Key)) || (n.Value != nil && isAssigned(n.Value))
+		case *ast.UnaryExpr:
+			found = found || (n.Op == token.AND && isAssigned(n.X))
+		}
+		return !found
+	})
+	return found
+}
+
+/**
+ * checks whether an expression is an identifier that refers to a variable
+ */
+func isObject(expr ast.Expr, object types.Object, info *types.Info) bool {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	return ok && info.ObjectOf(ident) == object
+}
+
+/**
+ * returns the index of the statement that is a node, or the length of the list if there is none
+ */
+func indexOf(statements []ast.Stmt, node ast.Node) int {
+	for i, statement := range statements {
+		if statement == node {
+			return i
+		}
+	}
+	return len(statements)
+}
@@ -0,0 +1,558 @@
+package stringmutation
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+)
+
+// Analyzer is a golang.org/x/tools/go/analysis style linter pass.
+// Use this with the Vet-style infrastructure.
+var Analyzer = &analysis.Analyzer{
+	Name:             "stringmutation",
+	Doc:              "reports writes into byte slices that share memory with immutable strings",
+	Run:              run,
+	Requires:         []*analysis.Analyzer{inspect.Analyzer},
+	RunDespiteErrors: true,
+	FactTypes:        []analysis.Fact{new(mutatesParams), new(returnsStringBytes)},
+}
+
+// the ways a byte slice can be modified
+type mutationKind int
+
+const (
+	indexMutation mutationKind = iota
+	copyMutation
+	appendMutation
+	callMutation
+)
+
+// mutation is a modification of the contents of a slice
+type mutation struct {
+	kind   mutationKind
+	target ast.Expr
+	pos    token.Pos
+	callee *types.Func
+}
+
+// parameter is a slice parameter of a declared function
+type parameter struct {
+	function *types.Func
+	index    int
+}
+
+// stringBytes tracks the variables of a package that hold byte slices sharing memory with a string. A variable is
+// tracked if any assignment to it is such a slice. Where it is used, the closest assignment in the code that runs right
+// before decides whether it still holds the slice, but assignments in branches, loops or closures are not followed
+type stringBytes struct {
+	pass *analysis.Pass
+	// tracked slice variables, mapped to whether their capacity may be larger than their length
+	variables map[types.Object]bool
+	// reflect.StringHeader variables derived by casting a string
+	stringHeaders map[types.Object]bool
+	// reflect.SliceHeader variables whose Data field was copied from a string header
+	stringDataHeaders map[types.Object]bool
+	// reflect.SliceHeader variables derived by casting a slice variable, mapped to that variable
+	sliceHeaders map[types.Object]types.Object
+}
+
+/**
+ * run is the entry point to the analysis pass
+ */
+func run(pass *analysis.Pass) (interface{}, error) {
+	// get results from required inspect analyzer
+	inspectResult := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	s := &stringBytes{
+		pass:              pass,
+		variables:         make(map[types.Object]bool),
+		stringHeaders:     make(map[types.Object]bool),
+		stringDataHeaders: make(map[types.Object]bool),
+		sliceHeaders:      make(map[types.Object]types.Object),
+	}
+	params := sliceParameters(inspectResult, pass)
+
+	// find the slices sharing memory with strings and the parameters that functions modify. Because these depend on
+	// each other and on the facts of called functions in the package, iterate until nothing changes anymore
+	nodeFilter := []ast.Node{
+		(*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil), (*ast.IncDecStmt)(nil), (*ast.CallExpr)(nil),
+		(*ast.ReturnStmt)(nil),
+	}
+	for changed := true; changed; {
+		changed = false
+		mutated := make(map[*types.Func]map[int]bool)
+
+		inspectResult.WithStack(nodeFilter, func(n ast.Node, push bool, stack []ast.Node) bool {
+			if !push {
+				return true
+			}
+			switch node := n.(type) {
+			case *ast.AssignStmt:
+				changed = s.assign(node.Lhs, node.Rhs, stack) || changed
+			case *ast.ValueSpec:
+				lhs := make([]ast.Expr, len(node.Names))
+				for i, name := range node.Names {
+					lhs[i] = name
+				}
+				changed = s.assign(lhs, node.Values, stack) || changed
+			case *ast.ReturnStmt:
+				changed = s.returns(node, stack) || changed
+			}
+
+			// remember the parameters of declared functions that are modified
+			for _, m := range mutations(n, pass) {
+				if m.kind == appendMutation {
+					continue
+				}
+				if param, ok := params[rootObject(m.target, pass)]; ok {
+					if mutated[param.function] == nil {
+						mutated[param.function] = make(map[int]bool)
+					}
+					mutated[param.function][param.index] = true
+				}
+			}
+			return true
+		})
+
+		for function, indices := range mutated {
+			changed = exportMutatedParams(function, indices, pass) || changed
+		}
+	}
+
+	// now, report all modifications of slices that share memory with strings
+	nodeFilter = []ast.Node{(*ast.AssignStmt)(nil), (*ast.IncDecStmt)(nil), (*ast.CallExpr)(nil)}
+	inspectResult.WithStack(nodeFilter, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		for _, m := range mutations(n, pass) {
+			tracked, spareCapacity := s.tracked(m.target, stack)
+			if !tracked {
+				continue
+			}
+			switch m.kind {
+			case indexMutation:
+				pass.Reportf(m.pos, "assigning to an element of a byte slice that shares memory with an immutable string")
+			case copyMutation:
+				pass.Reportf(m.pos, "copying into a byte slice that shares memory with an immutable string")
+			case appendMutation:
+				// appending only writes into the existing memory if there is capacity left
+				if spareCapacity {
+					pass.Reportf(m.pos, "appending to a byte slice that shares memory with an immutable string may "+
+						"overwrite the string data")
+				}
+			case callMutation:
+				pass.Reportf(m.pos, "passing a byte slice that shares memory with an immutable string to %s, which "+
+					"modifies it", m.callee.Name())
+			}
+		}
+		return true
+	})
+
+	return nil, nil
+}
+
+/**
+ * collects the slice parameters of the declared functions in the package
+ */
+func sliceParameters(inspectResult *inspector.Inspector, pass *analysis.Pass) map[types.Object]parameter {
+	params := make(map[types.Object]parameter)
+	inspectResult.Preorder([]ast.Node{(*ast.FuncDecl)(nil)}, func(n ast.Node) {
+		function, ok := pass.TypesInfo.Defs[n.(*ast.FuncDecl).Name].(*types.Func)
+		if !ok {
+			return
+		}
+		signature := function.Type().(*types.Signature)
+		for i := 0; i < signature.Params().Len(); i++ {
+			param := signature.Params().At(i)
+			if _, ok := param.Type().Underlying().(*types.Slice); ok {
+				params[param] = parameter{function: function, index: i}
+			}
+		}
+	})
+	return params
+}
+
+/**
+ * records what the assignment of values to targets means for the tracked slices and headers. Returns true if anything
+ * new was found
+ */
+func (s *stringBytes) assign(lhs []ast.Expr, rhs []ast.Expr, stack []ast.Node) bool {
+	if len(lhs) != len(rhs) {
+		return false
+	}
+	changed := false
+	for i := range lhs {
+		value := ast.Unparen(rhs[i])
+
+		// assignments to the Data field of a slice header, copying it from a string header
+		if selector, ok := lhs[i].(*ast.SelectorExpr); ok {
+			header := s.objectOf(selector.X)
+			if selector.Sel.Name == "Data" && header != nil && s.isStringData(value) && !s.stringDataHeaders[header] {
+				s.stringDataHeaders[header] = true
+				changed = true
+			}
+			continue
+		}
+
+		object := s.objectOf(lhs[i])
+		if object == nil {
+			continue
+		}
+
+		// definitions of header variables
+		if target, source, ok := headerCast(value, s.pass); ok {
+			if target == "StringHeader" && isStringPointer(source, s.pass) && !s.stringHeaders[object] {
+				s.stringHeaders[object] = true
+				changed = true
+			}
+			if slice := s.objectOf(addressOperand(source)); target == "SliceHeader" && slice != nil &&
+				s.sliceHeaders[object] == nil {
+				s.sliceHeaders[object] = slice
+				changed = true
+			}
+			continue
+		}
+		if literal, ok := headerLiteral(value, s.pass); ok {
+			for _, elt := range literal.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok && isIdent(kv.Key, "Data") && s.isStringData(kv.Value) &&
+					!s.stringDataHeaders[object] {
+					s.stringDataHeaders[object] = true
+					changed = true
+				}
+			}
+			continue
+		}
+
+		// slices sharing memory with a string
+		if tracked, spareCapacity := s.tracked(value, stack); tracked {
+			if previous, ok := s.variables[object]; !ok || (spareCapacity && !previous) {
+				s.variables[object] = spareCapacity
+				changed = true
+			}
+		}
+	}
+
+	// slice variables whose header got the Data field of a string
+	for header, slice := range s.sliceHeaders {
+		if s.stringDataHeaders[header] && !s.variables[slice] {
+			s.variables[slice] = true
+			changed = true
+		}
+	}
+	return changed
+}
+
+/**
+ * exports the fact that the declared function containing a return statement returns a slice sharing memory with a
+ * string, if it does. Returns true if the fact is new
+ */
+func (s *stringBytes) returns(ret *ast.ReturnStmt, stack []ast.Node) bool {
+	// only return statements of declared functions, not of closures, are considered
+	var decl *ast.FuncDecl
+	for i := len(stack) - 1; i >= 0 && decl == nil; i-- {
+		switch node := stack[i].(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.FuncDecl:
+			decl = node
+		}
+	}
+	if decl == nil || decl.Type.Results == nil {
+		return false
+	}
+	function, ok := s.pass.TypesInfo.Defs[decl.Name].(*types.Func)
+	if !ok {
+		return false
+	}
+
+	// a bare return returns the named results
+	results := ret.Results
+	if len(results) == 0 {
+		for _, field := range decl.Type.Results.List {
+			for _, name := range field.Names {
+				results = append(results, name)
+			}
+		}
+	}
+
+	for _, result := range results {
+		tracked, spareCapacity := s.tracked(result, stack)
+		if !tracked {
+			continue
+		}
+		previous := new(returnsStringBytes)
+		if s.pass.ImportObjectFact(function, previous) && (previous.SpareCapacity || !spareCapacity) {
+			continue
+		}
+		s.pass.ExportObjectFact(function, &returnsStringBytes{SpareCapacity: spareCapacity})
+		return true
+	}
+	return false
+}
+
+/**
+ * checks whether an expression, used at the node at the end of a stack, is a byte slice that shares memory with a
+ * string. The second return value is true if the capacity of the slice may be larger than its length
+ */
+func (s *stringBytes) tracked(expr ast.Expr, stack []ast.Node) (bool, bool) {
+	info := s.pass.TypesInfo
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		object := info.ObjectOf(e)
+		spareCapacity, ok := s.variables[object]
+		if !ok {
+			return false, false
+		}
+		// the variable might have been assigned a different slice since
+		if value, valueStack, ok := precedingValue(object, stack, info); ok {
+			if value == nil {
+				return false, false
+			}
+			return s.tracked(value, valueStack)
+		}
+		return true, spareCapacity
+	case *ast.SliceExpr:
+		// a slice of a tracked slice has the remaining capacity of it
+		tracked, _ := s.tracked(e.X, stack)
+		return tracked, true
+	case *ast.StarExpr:
+		// a dereferenced cast of a string, or of a header with the Data of a string, to a slice. The capacity of a
+		// slice cast from a string is whatever follows the string header in memory
+		call, ok := ast.Unparen(e.X).(*ast.CallExpr)
+		if !ok || !isSlicePointerConversion(call, s.pass) {
+			return false, false
+		}
+		source := ast.Unparen(call.Args[0]).(*ast.CallExpr).Args[0]
+		if isStringPointer(source, s.pass) {
+			return true, true
+		}
+		header := s.objectOf(source)
+		return header != nil && s.stringDataHeaders[header], true
+	case *ast.CallExpr:
+		// unsafe.Slice(unsafe.StringData(s), len(s))
+		if resolve.IsPackageObject(info, e.Fun, "unsafe", "Slice") && len(e.Args) == 2 {
+			data, ok := ast.Unparen(e.Args[0]).(*ast.CallExpr)
+			return ok && resolve.IsPackageObject(info, data.Fun, "unsafe", "StringData"), false
+		}
+		// functions that return such slices
+		callee := typeutil.StaticCallee(info, e)
+		fact := new(returnsStringBytes)
+		if callee != nil && s.pass.ImportObjectFact(callee, fact) {
+			return true, fact.SpareCapacity
+		}
+	}
+	return false, false
+}
+
+/**
+ * checks whether an expression is the Data field of a string header
+ */
+func (s *stringBytes) isStringData(expr ast.Expr) bool {
+	selector, ok := ast.Unparen(expr).(*ast.SelectorExpr)
+	if !ok || selector.Sel.Name != "Data" {
+		return false
+	}
+	header := s.objectOf(selector.X)
+	return header != nil && s.stringHeaders[header]
+}
+
+/**
+ * returns the variable that an identifier refers to, or nil if the expression is not an identifier
+ */
+func (s *stringBytes) objectOf(expr ast.Expr) types.Object {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	variable, ok := s.pass.TypesInfo.ObjectOf(ident).(*types.Var)
+	if !ok {
+		return nil
+	}
+	return variable
+}
+
+/**
+ * returns the modifications of slice contents that a node performs
+ */
+func mutations(n ast.Node, pass *analysis.Pass) []mutation {
+	var result []mutation
+	switch node := n.(type) {
+	case *ast.AssignStmt:
+		// assignments to slice elements
+		for _, lhs := range node.Lhs {
+			if target, ok := indexedSlice(lhs, pass); ok {
+				result = append(result, mutation{kind: indexMutation, target: target, pos: node.Pos()})
+			}
+		}
+	case *ast.IncDecStmt:
+		if target, ok := indexedSlice(node.X, pass); ok {
+			result = append(result, mutation{kind: indexMutation, target: target, pos: node.Pos()})
+		}
+	case *ast.CallExpr:
+		// the built-in copy and append functions write to their first argument
+		if builtin, ok := pass.TypesInfo.Uses[calleeIdent(node)].(*types.Builtin); ok && len(node.Args) > 0 {
+			switch builtin.Name() {
+			case "copy":
+				result = append(result, mutation{kind: copyMutation, target: node.Args[0], pos: node.Pos()})
+			case "append":
+				result = append(result, mutation{kind: appendMutation, target: node.Args[0], pos: node.Pos()})
+			}
+			return result
+		}
+		// other functions that are known to write to their arguments
+		indices, callee := mutatedArguments(node, pass)
+		for _, i := range indices {
+			if i < len(node.Args) {
+				result = append(result, mutation{kind: callMutation, target: node.Args[i], pos: node.Args[i].Pos(),
+					callee: callee})
+			}
+		}
+	}
+	return result
+}
+
+/**
+ * checks whether an expression is an element of a slice, and returns the slice if so
+ */
+func indexedSlice(expr ast.Expr, pass *analysis.Pass) (ast.Expr, bool) {
+	index, ok := ast.Unparen(expr).(*ast.IndexExpr)
+	if !ok {
+		return nil, false
+	}
+	t := pass.TypesInfo.TypeOf(index.X)
+	if t == nil {
+		return nil, false
+	}
+	if _, ok := t.Underlying().(*types.Slice); !ok {
+		return nil, false
+	}
+	return index.X, true
+}
+
+/**
+ * returns the identifier of the function that a call expression calls, or nil if it is not an identifier
+ */
+func calleeIdent(call *ast.CallExpr) *ast.Ident {
+	ident, _ := ast.Unparen(call.Fun).(*ast.Ident)
+	return ident
+}
+
+/**
+ * returns the variable at the root of a slice expression, like b in b[1:]
+ */
+func rootObject(expr ast.Expr, pass *analysis.Pass) types.Object {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.SliceExpr:
+		return rootObject(e.X, pass)
+	case *ast.Ident:
+		return pass.TypesInfo.ObjectOf(e)
+	}
+	return nil
+}
+
+/**
+ * checks if an expression is a cast of the form (*reflect.SliceHeader)(unsafe.Pointer(p)) or the same with
+ * reflect.StringHeader, and returns the name of the header type and p
+ */
+func headerCast(expr ast.Expr, pass *analysis.Pass) (string, ast.Expr, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return "", nil, false
+	}
+	target, ok := pass.TypesInfo.Types[call.Fun]
+	if !ok || !target.IsType() {
+		return "", nil, false
+	}
+	pointer, ok := target.Type.(*types.Pointer)
+	if !ok {
+		return "", nil, false
+	}
+	name := resolve.ReflectHeaderName(pointer.Elem())
+	if name == "" {
+		return "", nil, false
+	}
+	source, ok := ast.Unparen(call.Args[0]).(*ast.CallExpr)
+	if !ok || !resolve.IsUnsafePointerConversion(pass.TypesInfo, source) {
+		return "", nil, false
+	}
+	return name, source.Args[0], true
+}
+
+/**
+ * checks if an expression is a reflect header composite literal, possibly with its address taken
+ */
+func headerLiteral(expr ast.Expr, pass *analysis.Pass) (*ast.CompositeLit, bool) {
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		expr = unary.X
+	}
+	literal, ok := expr.(*ast.CompositeLit)
+	if !ok || resolve.ReflectHeaderName(pass.TypesInfo.TypeOf(literal)) == "" {
+		return nil, false
+	}
+	return literal, true
+}
+
+/**
+ * checks if a call is a conversion of the form (*[]T)(unsafe.Pointer(p))
+ */
+func isSlicePointerConversion(call *ast.CallExpr, pass *analysis.Pass) bool {
+	if len(call.Args) != 1 {
+		return false
+	}
+	target, ok := pass.TypesInfo.Types[call.Fun]
+	if !ok || !target.IsType() {
+		return false
+	}
+	pointer, ok := target.Type.Underlying().(*types.Pointer)
+	if !ok {
+		return false
+	}
+	if _, ok := pointer.Elem().Underlying().(*types.Slice); !ok {
+		return false
+	}
+	source, ok := ast.Unparen(call.Args[0]).(*ast.CallExpr)
+	return ok && resolve.IsUnsafePointerConversion(pass.TypesInfo, source)
+}
+
+/**
+ * checks whether an expression is a pointer to a string
+ */
+func isStringPointer(expr ast.Expr, pass *analysis.Pass) bool {
+	t := pass.TypesInfo.TypeOf(expr)
+	if t == nil {
+		return false
+	}
+	pointer, ok := t.Underlying().(*types.Pointer)
+	if !ok {
+		return false
+	}
+	basic, ok := pointer.Elem().Underlying().(*types.Basic)
+	return ok && basic.Kind() == types.String
+}
+
+/**
+ * returns the operand of an address operator, like x in &x
+ */
+func addressOperand(expr ast.Expr) ast.Expr {
+	unary, ok := ast.Unparen(expr).(*ast.UnaryExpr)
+	if !ok || unary.Op != token.AND {
+		return nil
+	}
+	return unary.X
+}
+
+/**
+ * checks whether an expression is an identifier with a name
+ */
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
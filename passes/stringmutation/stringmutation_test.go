@@ -0,0 +1,22 @@
+package stringmutation_test
+
+import (
+	"github.com/jlauinger/go-safer/passes/stringmutation"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"testing"
+)
+
+func Test(t *testing.T) {
+	// use go vet infrastructure testing and supply annotated code examples
+	testdata := analysistest.TestData()
+	testPackages := []string{
+		"bad/header_cast",
+		"bad/unsafe_slice",
+		"bad/header_pattern",
+		"bad/across_packages",
+
+		"good/copied_string",
+		"good/stringlib",
+	}
+	analysistest.Run(t, testdata, stringmutation.Analyzer, testPackages...)
+}
@@ -0,0 +1,14 @@
+package across_packages
+
+import "good/stringlib"
+
+func fill(b []byte) { // want fill:"mutatesParams\\[0\\]"
+	copy(b, "x")
+}
+
+func Mutate(s string) {
+	b := stringlib.ToBytes(s)
+	stringlib.Upper(b)         // want "passing a byte slice that shares memory with an immutable string to Upper, which modifies it"
+	stringlib.UpperFirst(1, b) // want "passing a byte slice that shares memory with an immutable string to UpperFirst, which modifies it"
+	fill(b[1:])                // want "passing a byte slice that shares memory with an immutable string to fill, which modifies it"
+}
@@ -0,0 +1,16 @@
+package header_cast
+
+import "unsafe"
+
+func MutateCast(s string) {
+	b := *(*[]byte)(unsafe.Pointer(&s))
+	b[0] = 'x'         // want "assigning to an element of a byte slice that shares memory with an immutable string"
+	b[1]++             // want "assigning to an element of a byte slice that shares memory with an immutable string"
+	copy(b, "abc")     // want "copying into a byte slice that shares memory with an immutable string"
+	_ = append(b, 'x') // want "appending to a byte slice that shares memory with an immutable string may overwrite the string data"
+}
+
+func MutateResliced(s string) {
+	tail := (*(*[]byte)(unsafe.Pointer(&s)))[1:]
+	tail[0] = 'x' // want "assigning to an element of a byte slice that shares memory with an immutable string"
+}
@@ -0,0 +1,48 @@
+package header_pattern
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+func toBytes(s string) (b []byte) { // want toBytes:"returnsStringBytes with spare capacity"
+	sH := (*reflect.StringHeader)(unsafe.Pointer(&s))
+	bH := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	bH.Data = sH.Data
+	bH.Len = sH.Len
+	bH.Cap = sH.Len
+	return
+}
+
+func literalToBytes(s string) []byte { // want literalToBytes:"returnsStringBytes with spare capacity"
+	sH := (*reflect.StringHeader)(unsafe.Pointer(&s))
+	bH := &reflect.SliceHeader{Data: sH.Data, Len: sH.Len, Cap: sH.Len}
+	return *(*[]byte)(unsafe.Pointer(bH))
+}
+
+type MysteryType reflect.SliceHeader
+
+type stringHeader struct {
+	Data uintptr
+	Len  int
+}
+
+func lookAlikeToBytes(s string) (b []byte) { // want lookAlikeToBytes:"returnsStringBytes with spare capacity"
+	sH := (*stringHeader)(unsafe.Pointer(&s))
+	bH := (*MysteryType)(unsafe.Pointer(&b))
+	bH.Data = sH.Data
+	bH.Len = sH.Len
+	bH.Cap = sH.Len
+	return
+}
+
+func Mutate(s string) {
+	b := toBytes(s)
+	b[0] = 'x' // want "assigning to an element of a byte slice that shares memory with an immutable string"
+
+	c := literalToBytes(s)
+	c[0] = 'x' // want "assigning to an element of a byte slice that shares memory with an immutable string"
+
+	d := lookAlikeToBytes(s)
+	d[0] = 'x' // want "assigning to an element of a byte slice that shares memory with an immutable string"
+}
@@ -0,0 +1,42 @@
+package unsafe_slice
+
+import (
+	"encoding/binary"
+	"io"
+	"unsafe"
+)
+
+func MutateSlice(s string, r io.Reader) {
+	b := unsafe.Slice(unsafe.StringData(s), len(s))
+	b[0] = 'x'                       // want "assigning to an element of a byte slice that shares memory with an immutable string"
+	_ = append(b, 'x')               // ok
+	_ = append(b[:1], 'x')           // want "appending to a byte slice that shares memory with an immutable string may overwrite the string data"
+	io.ReadFull(r, b)                // want "passing a byte slice that shares memory with an immutable string to ReadFull, which modifies it"
+	binary.BigEndian.PutUint32(b, 1) // want "passing a byte slice that shares memory with an immutable string to PutUint32, which modifies it"
+	r.Read(b)                        // want "passing a byte slice that shares memory with an immutable string to Read, which modifies it"
+}
+
+func ReassignedInBranch(s string, copied bool) {
+	b := unsafe.Slice(unsafe.StringData(s), len(s))
+	if copied {
+		b = []byte(s)
+	}
+	b[0] = 'x' // want "assigning to an element of a byte slice that shares memory with an immutable string"
+}
+
+func ReassignedFromString(s string) {
+	b := make([]byte, len(s))
+	b[0] = 'x' // ok
+	b = unsafe.Slice(unsafe.StringData(s), len(s))
+	b[0] = 'x' // want "assigning to an element of a byte slice that shares memory with an immutable string"
+	c := b[1:]
+	c[0] = 'x' // want "assigning to an element of a byte slice that shares memory with an immutable string"
+}
+
+func ReassignedInLoop(s string, n int) {
+	b := make([]byte, len(s))
+	for i := 0; i < n; i++ {
+		b[0] = 'x' // want "assigning to an element of a byte slice that shares memory with an immutable string"
+		b = unsafe.Slice(unsafe.StringData(s), len(s))
+	}
+}
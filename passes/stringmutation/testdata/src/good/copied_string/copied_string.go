@@ -0,0 +1,46 @@
+package copied_string
+
+import (
+	"bytes"
+	"good/stringlib"
+	"unsafe"
+)
+
+func MutateCopy(s string) {
+	b := []byte(s)
+	b[0] = 'x'     // ok
+	copy(b, "abc") // ok
+	stringlib.Upper(b)
+}
+
+func ReadOnly(s string) bool {
+	b := unsafe.Slice(unsafe.StringData(s), len(s))
+	_ = append(b, '!') // ok
+	return bytes.HasPrefix(b, []byte("x"))
+}
+
+func BytesToString(b []byte) string {
+	// the string shares memory with b, which is fine as long as neither is modified
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
+
+func Reassigned(s string) []byte {
+	b := unsafe.Slice(unsafe.StringData(s), len(s))
+	if bytes.HasPrefix(b, []byte("x")) {
+		return nil
+	}
+	b = make([]byte, len(s))
+	copy(b, s) // ok
+	b[0] = 'x' // ok
+	return b
+}
+
+func Shadowed(s string) {
+	b := unsafe.Slice(unsafe.StringData(s), len(s))
+	_ = len(b)
+	{
+		var b []byte
+		b = append(b, s...)
+		b[0] = 'x' // ok
+	}
+}
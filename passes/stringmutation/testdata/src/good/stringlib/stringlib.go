@@ -0,0 +1,17 @@
+package stringlib
+
+import "unsafe"
+
+func ToBytes(s string) []byte { // want ToBytes:"returnsStringBytes"
+	return unsafe.Slice(unsafe.StringData(s), len(s))
+}
+
+func Upper(b []byte) { // want Upper:"mutatesParams\\[0\\]"
+	for i := range b {
+		b[i] -= 'a' - 'A'
+	}
+}
+
+func UpperFirst(n int, b []byte) { // want UpperFirst:"mutatesParams\\[1\\]"
+	Upper(b[:n])
+}
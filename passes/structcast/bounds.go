@@ -0,0 +1,77 @@
+package structcast
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// sizeGroup holds the architectures where two types have the same pair of sizes
+type sizeGroup struct {
+	src, dst int64
+	archs    []string
+}
+
+/**
+ * groups the architectures by the sizes of two types, keeping only those where the sizes are of interest
+ */
+func groupSizes(src types.Type, dst types.Type, archs []archSizes, include func(src, dst int64) bool) []*sizeGroup {
+	var groups []*sizeGroup
+	for _, arch := range archs {
+		srcSize, dstSize := arch.sizes.Sizeof(src), arch.sizes.Sizeof(dst)
+		if !include(srcSize, dstSize) {
+			continue
+		}
+		var group *sizeGroup
+		for _, g := range groups {
+			if g.src == srcSize && g.dst == dstSize {
+				group = g
+			}
+		}
+		if group == nil {
+			group = &sizeGroup{src: srcSize, dst: dstSize}
+			groups = append(groups, group)
+		}
+		group.archs = append(group.archs, arch.arch)
+	}
+	return groups
+}
+
+/**
+ * checks whether a cast to a struct or array type reads beyond the end of the source value, because the destination
+ * is larger than the source on some architecture. Returns a message that shows both sizes if so
+ */
+func checkOutOfBoundsCast(src types.Type, dst types.Type, archs []archSizes) (string, bool) {
+	// the size of the source value is only known for structs and arrays
+	switch src.(type) {
+	case *types.Struct, *types.Array:
+	default:
+		return "", false
+	}
+
+	// the destination must be a struct or array
+	var kind string
+	switch dst.(type) {
+	case *types.Struct:
+		kind = "struct"
+	case *types.Array:
+		kind = "array"
+	default:
+		return "", false
+	}
+
+	// collect the architectures where the destination is larger, grouped by the sizes on these architectures
+	groups := groupSizes(src, dst, archs, func(src, dst int64) bool { return dst > src })
+	if len(groups) == 0 {
+		return "", false
+	}
+
+	// describe each group of architectures with the sizes of source and destination
+	descriptions := make([]string, len(groups))
+	for i, group := range groups {
+		descriptions[i] = fmt.Sprintf("source is %d bytes and destination is %d bytes on %s", group.src, group.dst,
+			strings.Join(group.archs, ", "))
+	}
+	return fmt.Sprintf("unsafe cast to a larger %s reads out of bounds: %s", kind, strings.Join(descriptions, "; ")),
+		true
+}
@@ -0,0 +1,70 @@
+package structcast
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+)
+
+/**
+ * checks the casts of the form (*T)(unsafe.Pointer(p)) where p is a typed pointer, without following unsafe.Pointer
+ * values through variables, fields and helper functions. This works on packages with type errors, which have no SSA
+ * form
+ */
+func checkDirectCasts(inspectResult *inspector.Inspector, archs []archSizes, pass *analysis.Pass) {
+	inspectResult.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		node := n.(*ast.CallExpr)
+
+		// find casts to a pointer type whose argument is a conversion to unsafe.Pointer
+		target, ok := pass.TypesInfo.Types[node.Fun]
+		if !ok || !target.IsType() || len(node.Args) != 1 {
+			return
+		}
+		dstPointer, ok := target.Type.Underlying().(*types.Pointer)
+		if !ok {
+			return
+		}
+		conversion, ok := ast.Unparen(node.Args[0]).(*ast.CallExpr)
+		if !ok || !resolve.IsUnsafePointerConversion(pass.TypesInfo, conversion) {
+			return
+		}
+
+		// the source must be a typed pointer
+		srcType := pass.TypesInfo.TypeOf(conversion.Args[0])
+		if srcType == nil {
+			return
+		}
+		srcPointer, ok := srcType.Underlying().(*types.Pointer)
+		if !ok {
+			return
+		}
+
+		src := pointee{typ: srcPointer.Elem(), exact: isVariableAddress(conversion.Args[0], pass)}
+		for _, message := range checkCast(src, dstPointer, archs, pass) {
+			pass.Reportf(node.Pos(), "%s", message)
+		}
+	})
+}
+
+/**
+ * checks whether an expression is the address of a variable or a composite literal, like &x or &T{}
+ */
+func isVariableAddress(expr ast.Expr, pass *analysis.Pass) bool {
+	address, ok := ast.Unparen(expr).(*ast.UnaryExpr)
+	if !ok || address.Op != token.AND {
+		return false
+	}
+	switch x := ast.Unparen(address.X).(type) {
+	case *ast.CompositeLit:
+		return true
+	case *ast.Ident:
+		variable, ok := pass.TypesInfo.ObjectOf(x).(*types.Var)
+		return ok && !variable.IsField()
+	}
+	return false
+}
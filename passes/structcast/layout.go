@@ -0,0 +1,160 @@
+package structcast
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// fieldLayout describes the position of a struct field in memory on one architecture
+type fieldLayout struct {
+	name   string
+	typ    types.Type
+	offset int64
+	size   int64
+}
+
+// structLayout describes the memory layout of a struct type on one architecture as a flat list of fields. Blank fields
+// are not included, because they are only used as explicit padding
+type structLayout struct {
+	fields []fieldLayout
+	size   int64
+}
+
+// arrays with more elements than this are compared as a whole instead of element by element
+const maxFlattenedArrayLength = 64
+
+// archSizes holds the sizes of the types on one architecture
+type archSizes struct {
+	arch  string
+	sizes types.Sizes
+}
+
+/**
+ * parses a comma-separated list of GOARCH values into the type sizes for each architecture
+ */
+func parseArchitectures(list string) ([]archSizes, error) {
+	var result []archSizes
+	for _, arch := range strings.Split(list, ",") {
+		arch = strings.TrimSpace(arch)
+		if arch == "" {
+			continue
+		}
+		sizes := types.SizesFor("gc", arch)
+		if sizes == nil {
+			return nil, fmt.Errorf("unknown architecture %q", arch)
+		}
+		result = append(result, archSizes{arch: arch, sizes: sizes})
+	}
+	return result, nil
+}
+
+/**
+ * computes the memory layout of a struct type using the given type sizes. Nested structs, embedded structs and arrays
+ * are flattened into their fields and elements, so that structs with the same memory layout but a different nesting
+ * compare equal
+ */
+func layoutOf(s *types.Struct, sizes types.Sizes) structLayout {
+	layout := structLayout{size: sizes.Sizeof(s)}
+	layout.add("", s, 0, sizes)
+	return layout
+}
+
+/**
+ * adds the fields that a value of a type at an offset consists of to the layout. Named types are resolved to their
+ * underlying types
+ */
+func (layout *structLayout) add(name string, t types.Type, offset int64, sizes types.Sizes) {
+	switch underlying := t.Underlying().(type) {
+	case *types.Struct:
+		// add each struct field, prefixed with the name of the struct
+		fields := make([]*types.Var, underlying.NumFields())
+		for i := range fields {
+			fields[i] = underlying.Field(i)
+		}
+		offsets := sizes.Offsetsof(fields)
+		for i, field := range fields {
+			if field.Name() == "_" {
+				continue
+			}
+			fieldName := field.Name()
+			if name != "" {
+				fieldName = name + "." + fieldName
+			}
+			layout.add(fieldName, field.Type(), offset+offsets[i], sizes)
+		}
+		return
+	case *types.Array:
+		// add each array element, unless the array is too large to compare it element by element
+		if underlying.Len() <= maxFlattenedArrayLength {
+			elemSize := sizes.Sizeof(underlying.Elem())
+			for i := int64(0); i < underlying.Len(); i++ {
+				layout.add(fmt.Sprintf("%s[%d]", name, i), underlying.Elem(), offset+i*elemSize, sizes)
+			}
+			return
+		}
+	case *types.Basic:
+		// strings consist of a data pointer and a length
+		if underlying.Kind() == types.String {
+			layout.addWords(name, sizes, offset, "data", "len")
+			return
+		}
+	case *types.Slice:
+		// slices consist of a data pointer, a length, and a capacity
+		layout.addWords(name, sizes, offset, "data", "len", "cap")
+		return
+	case *types.Interface:
+		// interfaces consist of a type or method table pointer and a data pointer
+		layout.addWords(name, sizes, offset, "type", "data")
+		return
+	}
+
+	// everything else, including pointers, maps, channels and functions, which are a single word, is added as is
+	layout.fields = append(layout.fields, fieldLayout{
+		name:   name,
+		typ:    t,
+		offset: offset,
+		size:   sizes.Sizeof(t),
+	})
+}
+
+/**
+ * finds the index of the first field where two layouts differ in offset or size. If all fields of the shorter layout
+ * match, the index is its length. The second return value is false if the layouts are the same
+ */
+func firstDivergingField(a, b structLayout) (int, bool) {
+	i := 0
+	for ; i < len(a.fields) && i < len(b.fields); i++ {
+		if a.fields[i].offset != b.fields[i].offset || a.fields[i].size != b.fields[i].size {
+			return i, true
+		}
+	}
+	return i, len(a.fields) != len(b.fields) || a.size != b.size
+}
+
+/**
+ * describes the field at an index of a layout for a diagnostic message, or the end of the struct if there is no field
+ * at that index
+ */
+func describeField(layout structLayout, i int, qualifier types.Qualifier) string {
+	if i >= len(layout.fields) {
+		return fmt.Sprintf("end of struct at offset %d", layout.size)
+	}
+	field := layout.fields[i]
+	return fmt.Sprintf("%s %s at offset %d", field.name, types.TypeString(field.typ, qualifier), field.offset)
+}
+
+/**
+ * adds the words that a multi-word built-in type like a string, slice or interface consists of to the layout. Words
+ * named data or type are pointers, all others are lengths or capacities
+ */
+func (layout *structLayout) addWords(name string, sizes types.Sizes, offset int64, words ...string) {
+	wordSize := sizes.Sizeof(types.Typ[types.Uintptr])
+	for i, word := range words {
+		t := types.Typ[types.Int]
+		if word == "data" || word == "type" {
+			t = types.Typ[types.UnsafePointer]
+		}
+		layout.add(name+"."+word, t, offset+int64(i)*wordSize, sizes)
+	}
+}
@@ -0,0 +1,243 @@
+package structcast
+
+import (
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// helper functions are followed up to this depth of nested calls
+const maxCallDepth = 8
+
+// pointee describes the value that an unsafe.Pointer points to
+type pointee struct {
+	typ types.Type
+	// exact is true if the pointer is the address of a variable of the type, like &x, rather than a pointer that might
+	// point into a larger value, like a pointer parameter or &buf[0]
+	exact bool
+}
+
+// pointerOrigins finds the values that unsafe.Pointer values were converted from. It remembers all values that are
+// stored to struct fields and global variables of the package
+type pointerOrigins struct {
+	fields  map[*types.Var][]ssa.Value
+	globals map[*ssa.Global][]ssa.Value
+}
+
+// callContext is a call to a helper function that is followed, so that its parameters can be resolved to the
+// arguments of the call
+type callContext struct {
+	call   *ssa.Call
+	parent *callContext
+	depth  int
+}
+
+// visit is a value that has been followed in a call context
+type visit struct {
+	value   ssa.Value
+	context *callContext
+}
+
+/**
+ * collects the values stored to struct fields and global variables in the functions of a package
+ */
+func newPointerOrigins(functions []*ssa.Function) *pointerOrigins {
+	origins := &pointerOrigins{
+		fields:  make(map[*types.Var][]ssa.Value),
+		globals: make(map[*ssa.Global][]ssa.Value),
+	}
+	for _, function := range functions {
+		for _, block := range function.Blocks {
+			for _, instr := range block.Instrs {
+				store, ok := instr.(*ssa.Store)
+				if !ok {
+					continue
+				}
+				switch addr := store.Addr.(type) {
+				case *ssa.FieldAddr:
+					field := structField(addr.X.Type().Underlying().(*types.Pointer).Elem(), addr.Field)
+					origins.fields[field] = append(origins.fields[field], store.Val)
+				case *ssa.Global:
+					origins.globals[addr] = append(origins.globals[addr], store.Val)
+				}
+			}
+		}
+	}
+	return origins
+}
+
+/**
+ * returns the field with an index of a struct type
+ */
+func structField(t types.Type, index int) *types.Var {
+	return t.Underlying().(*types.Struct).Field(index)
+}
+
+/**
+ * finds the value that an unsafe.Pointer value points to, by following it back to the conversion from a typed pointer.
+ * This only succeeds if all possible origins of the value agree on the pointee
+ */
+func (origins *pointerOrigins) pointee(value ssa.Value) (pointee, bool) {
+	found, ok := origins.follow(value, nil, make(map[visit]bool))
+	if !ok || len(found) == 0 {
+		return pointee{}, false
+	}
+	for _, other := range found[1:] {
+		if other.exact != found[0].exact || !types.Identical(other.typ, found[0].typ) {
+			return pointee{}, false
+		}
+	}
+	return found[0], true
+}
+
+/**
+ * collects the pointees that a value may have been converted from. It returns false if any origin is unknown. Values
+ * that are already being followed add nothing, which handles loops
+ */
+func (origins *pointerOrigins) follow(value ssa.Value, context *callContext,
+	visited map[visit]bool) ([]pointee, bool) {
+	if visited[visit{value, context}] {
+		return nil, true
+	}
+	visited[visit{value, context}] = true
+
+	switch v := value.(type) {
+	case *ssa.Convert:
+		// a conversion from a typed pointer is the origin we are looking for
+		pointer, ok := v.X.Type().Underlying().(*types.Pointer)
+		if !ok {
+			return nil, false
+		}
+		return []pointee{{typ: pointer.Elem(), exact: addressOfVariable(v.X, context)}}, true
+	case *ssa.ChangeType:
+		return origins.follow(v.X, context, visited)
+	case *ssa.Phi:
+		// a variable assigned on different paths, all of them must agree
+		return origins.followAll(v.Edges, context, visited)
+	case *ssa.UnOp:
+		// a load from a variable whose address is taken, a struct field or a global variable
+		if v.Op != token.MUL {
+			return nil, false
+		}
+		switch addr := v.X.(type) {
+		case *ssa.Alloc:
+			stored, ok := storedValues(addr)
+			if !ok {
+				return nil, false
+			}
+			return origins.followAll(stored, context, visited)
+		case *ssa.FieldAddr:
+			field := structField(addr.X.Type().Underlying().(*types.Pointer).Elem(), addr.Field)
+			return origins.followAll(origins.fields[field], context, visited)
+		case *ssa.Global:
+			return origins.followAll(origins.globals[addr], context, visited)
+		}
+	case *ssa.Field:
+		// a field of a struct value
+		return origins.followAll(origins.fields[structField(v.X.Type(), v.Field)], context, visited)
+	case *ssa.Call:
+		// the result of a helper function, if the function is declared in this package
+		return origins.followReturns(v, 0, context, visited)
+	case *ssa.Extract:
+		// one of multiple results of a helper function
+		if call, ok := v.Tuple.(*ssa.Call); ok {
+			return origins.followReturns(call, v.Index, context, visited)
+		}
+	case *ssa.Parameter:
+		// a parameter of a helper function that is followed is the argument of the call
+		if context == nil || context.call.Call.StaticCallee() != v.Parent() {
+			return nil, false
+		}
+		for i, param := range v.Parent().Params {
+			if param == v && i < len(context.call.Call.Args) {
+				return origins.follow(context.call.Call.Args[i], context.parent, visited)
+			}
+		}
+	}
+
+	// everything else, such as conversions from uintptr or parameters of the analyzed function, is unknown
+	return nil, false
+}
+
+/**
+ * collects the pointees of multiple values, all of which must be known
+ */
+func (origins *pointerOrigins) followAll(values []ssa.Value, context *callContext,
+	visited map[visit]bool) ([]pointee, bool) {
+	if len(values) == 0 {
+		return nil, false
+	}
+	var found []pointee
+	for _, value := range values {
+		pointees, ok := origins.follow(value, context, visited)
+		if !ok {
+			return nil, false
+		}
+		found = append(found, pointees...)
+	}
+	return found, true
+}
+
+/**
+ * collects the pointees of a result of a called function from all its return statements
+ */
+func (origins *pointerOrigins) followReturns(call *ssa.Call, index int, context *callContext,
+	visited map[visit]bool) ([]pointee, bool) {
+	callee := call.Call.StaticCallee()
+	if callee == nil || callee.Blocks == nil || (context != nil && context.depth >= maxCallDepth) {
+		return nil, false
+	}
+	calleeContext := &callContext{call: call, parent: context}
+	if context != nil {
+		calleeContext.depth = context.depth + 1
+	}
+
+	var results []ssa.Value
+	for _, block := range callee.Blocks {
+		if ret, ok := block.Instrs[len(block.Instrs)-1].(*ssa.Return); ok && index < len(ret.Results) {
+			results = append(results, ret.Results[index])
+		}
+	}
+	return origins.followAll(results, calleeContext, visited)
+}
+
+/**
+ * returns the values stored to a local variable, as long as its address is only used to store and load values
+ */
+func storedValues(alloc *ssa.Alloc) ([]ssa.Value, bool) {
+	var stored []ssa.Value
+	for _, referrer := range *alloc.Referrers() {
+		switch instr := referrer.(type) {
+		case *ssa.Store:
+			if instr.Addr != alloc {
+				return nil, false
+			}
+			stored = append(stored, instr.Val)
+		case *ssa.UnOp, *ssa.DebugRef:
+		default:
+			return nil, false
+		}
+	}
+	return stored, true
+}
+
+/**
+ * checks whether a pointer is the address of a local or global variable, possibly passed to a helper function
+ */
+func addressOfVariable(pointer ssa.Value, context *callContext) bool {
+	switch p := pointer.(type) {
+	case *ssa.Alloc, *ssa.Global:
+		return true
+	case *ssa.Parameter:
+		if context == nil || context.call.Call.StaticCallee() != p.Parent() {
+			return false
+		}
+		for i, param := range p.Parent().Params {
+			if param == p && i < len(context.call.Call.Args) {
+				return addressOfVariable(context.call.Call.Args[i], context.parent)
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package structcast
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+/**
+ * checks whether a cast between two struct types puts pointers of the source where the destination has non-pointer
+ * values, which hides them from the garbage collector, or the other way round, which makes the garbage collector
+ * follow arbitrary values. Returns a message for each of the two directions that happens on some architecture
+ */
+func checkPointerBitmaps(src types.Type, dst types.Type, archs []archSizes, qualifier types.Qualifier) []string {
+	// only struct casts are checked
+	srcStruct, ok := src.(*types.Struct)
+	if !ok {
+		return nil
+	}
+	dstStruct, ok := dst.(*types.Struct)
+	if !ok {
+		return nil
+	}
+
+	// compare the pointer bitmaps on every architecture, and remember the first mismatch in each direction on the
+	// first architecture where it happens
+	var hiddenArchs, fabricatedArchs []string
+	var hidden, fabricated string
+	for _, arch := range archs {
+		srcLayout := layoutOf(srcStruct, arch.sizes)
+		dstLayout := layoutOf(dstStruct, arch.sizes)
+
+		// only the memory that both structs share is reinterpreted
+		limit := srcLayout.size
+		if dstLayout.size < limit {
+			limit = dstLayout.size
+		}
+		srcPointers := pointerOffsets(srcStruct, arch.sizes, limit)
+		dstPointers := pointerOffsets(dstStruct, arch.sizes, limit)
+
+		if offset, ok := firstMissing(srcPointers, dstPointers); ok {
+			if hiddenArchs == nil {
+				hidden = describeOverlap(srcLayout, dstLayout, offset, qualifier)
+			}
+			hiddenArchs = append(hiddenArchs, arch.arch)
+		}
+		if offset, ok := firstMissing(dstPointers, srcPointers); ok {
+			if fabricatedArchs == nil {
+				fabricated = describeOverlap(srcLayout, dstLayout, offset, qualifier)
+			}
+			fabricatedArchs = append(fabricatedArchs, arch.arch)
+		}
+	}
+
+	var messages []string
+	if hiddenArchs != nil {
+		messages = append(messages, fmt.Sprintf("unsafe cast between structs hides a pointer from the garbage "+
+			"collector on %s: %s", strings.Join(hiddenArchs, ", "), hidden))
+	}
+	if fabricatedArchs != nil {
+		messages = append(messages, fmt.Sprintf("unsafe cast between structs makes the garbage collector treat a "+
+			"non-pointer value as a pointer on %s: %s", strings.Join(fabricatedArchs, ", "), fabricated))
+	}
+	return messages
+}
+
+/**
+ * computes the offsets of the words that the garbage collector treats as pointers in a value of a type, up to a limit
+ */
+func pointerOffsets(t types.Type, sizes types.Sizes, limit int64) map[int64]bool {
+	offsets := make(map[int64]bool)
+	addPointerOffsets(t, 0, sizes, limit, offsets)
+	return offsets
+}
+
+/**
+ * adds the offsets of the pointer words in a value of a type at an offset to the set of offsets
+ */
+func addPointerOffsets(t types.Type, offset int64, sizes types.Sizes, limit int64, offsets map[int64]bool) {
+	if offset >= limit {
+		return
+	}
+
+	wordSize := sizes.Sizeof(types.Typ[types.Uintptr])
+	switch underlying := t.Underlying().(type) {
+	case *types.Pointer, *types.Map, *types.Chan, *types.Signature, *types.Slice:
+		// these are a single pointer, or in case of slices start with the data pointer
+		offsets[offset] = true
+	case *types.Interface:
+		// both words of an interface are pointers
+		offsets[offset] = true
+		if offset+wordSize < limit {
+			offsets[offset+wordSize] = true
+		}
+	case *types.Basic:
+		// unsafe.Pointer is a pointer, and strings start with the data pointer
+		if underlying.Kind() == types.UnsafePointer || underlying.Kind() == types.String {
+			offsets[offset] = true
+		}
+	case *types.Struct:
+		fields := make([]*types.Var, underlying.NumFields())
+		for i := range fields {
+			fields[i] = underlying.Field(i)
+		}
+		for i, fieldOffset := range sizes.Offsetsof(fields) {
+			addPointerOffsets(fields[i].Type(), offset+fieldOffset, sizes, limit, offsets)
+		}
+	case *types.Array:
+		elemSize := sizes.Sizeof(underlying.Elem())
+		for i := int64(0); i < underlying.Len() && offset+i*elemSize < limit; i++ {
+			addPointerOffsets(underlying.Elem(), offset+i*elemSize, sizes, limit, offsets)
+		}
+	}
+}
+
+/**
+ * finds the lowest offset that is contained in the first set of offsets but not in the second
+ */
+func firstMissing(offsets map[int64]bool, other map[int64]bool) (int64, bool) {
+	var missing []int64
+	for offset := range offsets {
+		if !other[offset] {
+			missing = append(missing, offset)
+		}
+	}
+	if len(missing) == 0 {
+		return 0, false
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i] < missing[j] })
+	return missing[0], true
+}
+
+/**
+ * describes the source and destination fields that overlap at an offset for a diagnostic message
+ */
+func describeOverlap(srcLayout, dstLayout structLayout, offset int64, qualifier types.Qualifier) string {
+	return fmt.Sprintf("%s in source overlaps %s in destination at offset %d", describeFieldAt(srcLayout, offset,
+		qualifier), describeFieldAt(dstLayout, offset, qualifier), offset)
+}
+
+/**
+ * describes the field of a layout that contains an offset, or padding if there is none
+ */
+func describeFieldAt(layout structLayout, offset int64, qualifier types.Qualifier) string {
+	for _, field := range layout.fields {
+		if field.offset <= offset && offset < field.offset+field.size {
+			return fmt.Sprintf("%s %s", field.name, types.TypeString(field.typ, qualifier))
+		}
+	}
+	return "padding"
+}
@@ -0,0 +1,124 @@
+package structcast
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+)
+
+/**
+ * checks whether two element types differ in size on some architecture, which means that reinterpreting a slice of
+ * one as a slice of the other needs to scale the length. Returns a description of the sizes if so
+ */
+func checkElementSizes(src types.Type, dst types.Type, archs []archSizes, qualifier types.Qualifier) (string, bool) {
+	groups := groupSizes(src, dst, archs, func(src, dst int64) bool { return src != dst })
+	if len(groups) == 0 {
+		return "", false
+	}
+
+	// describe each group of architectures with the sizes of the source and destination elements
+	descriptions := make([]string, len(groups))
+	for i, group := range groups {
+		descriptions[i] = fmt.Sprintf("source element %s is %d bytes and destination element %s is %d bytes on %s",
+			types.TypeString(src, qualifier), group.src, types.TypeString(dst, qualifier), group.dst,
+			strings.Join(group.archs, ", "))
+	}
+	return strings.Join(descriptions, "; "), true
+}
+
+/**
+ * checks the elements of a cast between slices or arrays. Casting a slice keeps the length and capacity, which is
+ * wrong if the elements differ in size. The elements themselves are checked like a struct cast
+ */
+func checkElementsCast(src types.Type, dst types.Type, archs []archSizes, qualifier types.Qualifier) ([]string, bool) {
+	var srcElem, dstElem types.Type
+	var messages []string
+	switch srcType := src.(type) {
+	case *types.Slice:
+		dstType, ok := dst.(*types.Slice)
+		if !ok {
+			return nil, false
+		}
+		srcElem, dstElem = srcType.Elem(), dstType.Elem()
+		if sizes, ok := checkElementSizes(srcElem, dstElem, archs, qualifier); ok {
+			messages = append(messages, "unsafe cast between slices keeps the length although the element sizes "+
+				"differ: "+sizes)
+		}
+	case *types.Array:
+		dstType, ok := dst.(*types.Array)
+		if !ok {
+			return nil, false
+		}
+		srcElem, dstElem = srcType.Elem(), dstType.Elem()
+	default:
+		return nil, false
+	}
+
+	// compare the element layouts like a cast between the element types
+	srcElem, dstElem = srcElem.Underlying(), dstElem.Underlying()
+	if message, ok := checkIncompatibleStructsCast(srcElem, dstElem, archs, qualifier); ok {
+		messages = append(messages, message)
+	}
+	messages = append(messages, checkPointerBitmaps(srcElem, dstElem, archs, qualifier)...)
+	return messages, true
+}
+
+/**
+ * checks whether a call to unsafe.Slice reinterprets the elements of a slice as a different type, but keeps the
+ * length of the original slice, like unsafe.Slice((*B)(unsafe.Pointer(&as[0])), len(as)). Returns a message if so
+ */
+func checkUnsafeSlice(call *ssa.Call, origins *pointerOrigins, archs []archSizes,
+	qualifier types.Qualifier) (string, bool) {
+	// check that this is a call to unsafe.Slice, which is the only built-in function with that name
+	builtin, ok := call.Call.Value.(*ssa.Builtin)
+	if !ok || builtin.Name() != "Slice" || len(call.Call.Args) != 2 {
+		return "", false
+	}
+
+	// the pointer argument must be converted from a pointer to an element of a different type
+	conversion, ok := call.Call.Args[0].(*ssa.Convert)
+	if !ok || !resolve.IsUnsafePointerType(conversion.X.Type()) {
+		return "", false
+	}
+	src, ok := origins.pointee(conversion.X)
+	if !ok {
+		return "", false
+	}
+	dstElem := call.Call.Args[0].Type().Underlying().(*types.Pointer).Elem()
+	if resolve.ContainsTypeParam(src.typ) || resolve.ContainsTypeParam(dstElem) {
+		return "", false
+	}
+
+	// the length argument must be the unchanged length or capacity of a slice of source elements
+	length := call.Call.Args[1]
+	for {
+		converted, ok := length.(*ssa.Convert)
+		if !ok {
+			break
+		}
+		length = converted.X
+	}
+	lengthCall, ok := length.(*ssa.Call)
+	if !ok {
+		return "", false
+	}
+	lengthBuiltin, ok := lengthCall.Call.Value.(*ssa.Builtin)
+	if !ok || (lengthBuiltin.Name() != "len" && lengthBuiltin.Name() != "cap") {
+		return "", false
+	}
+	slice, ok := lengthCall.Call.Args[0].Type().Underlying().(*types.Slice)
+	if !ok || !types.Identical(slice.Elem(), src.typ) {
+		return "", false
+	}
+
+	// then, report if the element sizes differ
+	sizes, ok := checkElementSizes(src.typ, dstElem, archs, qualifier)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("unsafe.Slice length is not scaled by the element size ratio: %s", sizes), true
+}
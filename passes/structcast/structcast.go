@@ -1,194 +1,229 @@
 package structcast
 
 import (
+	"fmt"
 	"go/ast"
 	"go/token"
 	"go/types"
+	"strings"
+
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
 )
 
 // Analyzer is a golang.org/x/tools/go/analysis style linter pass.
 // Use this with the Vet-style infrastructure.
 var Analyzer = &analysis.Analyzer{
 	Name:             "structcast",
-	Doc:              "reports unsafe struct casts where the target struct contains architecture sized variables",
+	Doc:              "reports unsafe struct casts where the struct layouts differ between architectures",
 	Run:              run,
 	Requires:         []*analysis.Analyzer{inspect.Analyzer},
 	RunDespiteErrors: true,
 }
 
+// architectures is the comma-separated list of GOARCH values that struct layouts are compared on
+var architectures string
+
+func init() {
+	Analyzer.Flags.StringVar(&architectures, "archs", "386,amd64,arm,arm64,mips,wasm",
+		"comma-separated list of GOARCH values to compare struct layouts on")
+}
+
 /**
  * run is the entry point to the analysis pass
  */
 func run(pass *analysis.Pass) (interface{}, error) {
-	// get results from required inspect analyzer
-	inspectResult := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
-
-	// filter AST of package under analysis for CallExpr nodes
-	inspectResult.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
-		// check if the node is a misuse
-		node := n.(*ast.CallExpr)
-		if structCastWithMismatchingTargetLength(node, pass) {
-			// if so, report a warning
-			pass.Reportf(n.Pos(), "unsafe cast between structs with mismatching count of platform dependent field sizes")
+	// find the type sizes of the configured architectures
+	archs, err := parseArchitectures(architectures)
+	if err != nil {
+		return nil, err
+	}
+
+	// the SSA form cannot be built for packages with type errors, so only the casts that convert a typed pointer
+	// directly are checked there
+	if len(pass.TypeErrors) > 0 {
+		inspectResult := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+		checkDirectCasts(inspectResult, archs, pass)
+		return nil, nil
+	}
+
+	// collect the values that are stored to struct fields and global variables, so that unsafe.Pointer values can be
+	// followed through them
+	functions := packageFunctions(pass)
+	origins := newPointerOrigins(functions)
+
+	// find the conversions from unsafe.Pointer to other pointer types and the calls to unsafe.Slice in the SSA form of
+	// the package
+	for _, function := range functions {
+		for _, block := range function.Blocks {
+			for _, instr := range block.Instrs {
+				switch instr := instr.(type) {
+				case *ssa.Convert:
+					// check if the conversion is a misuse, and if so, report a warning for each problem
+					if instr.Pos() == token.NoPos {
+						continue
+					}
+					for _, message := range checkStructCast(instr, origins, archs, pass) {
+						pass.Reportf(instr.Pos(), "%s", message)
+					}
+				case *ssa.Call:
+					// check if a slice is reinterpreted using unsafe.Slice without scaling its length
+					if message, ok := checkUnsafeSlice(instr, origins, archs, types.RelativeTo(pass.Pkg)); ok {
+						pass.Reportf(instr.Pos(), "%s", message)
+					}
+				}
+			}
 		}
-	})
+	}
 
 	return nil, nil
 }
 
 /**
- * checks if a CallExpr node is a misused cast between incompatible architecture-dependent struct types
+ * builds the SSA form of the package and returns its functions, including anonymous functions and the package
+ * initializer
  */
-func structCastWithMismatchingTargetLength(expr *ast.CallExpr, pass *analysis.Pass) bool {
-	// first, check if this node represents a direct cast using unsafe
-	src, dst, ok := detectUnsafeCast(expr)
-	if !ok {
-		return false
+func packageFunctions(pass *analysis.Pass) []*ssa.Function {
+	program := ssa.NewProgram(pass.Fset, ssa.BuilderMode(0))
+	for _, imported := range pass.Pkg.Imports() {
+		program.CreatePackage(imported, nil, nil, true)
+	}
+	ssaPkg := program.CreatePackage(pass.Pkg, pass.Files, pass.TypesInfo, false)
+	ssaPkg.Build()
+
+	var functions []*ssa.Function
+	var addAnon func(function *ssa.Function)
+	addAnon = func(function *ssa.Function) {
+		functions = append(functions, function)
+		for _, anon := range function.AnonFuncs {
+			addAnon(anon)
+		}
 	}
-
-	// it is a cast. Get the source and destination types
-	srcType := getObjectType(src, pass)
-	dstType := getObjectType(dst, pass)
-
-	// then, check if the types are structs that contain a different amount of architecture-dependent types
-	return checkIncompatibleStructsCast(srcType, dstType)
-}
-
-/*
- * checks if an AST node is a cast using unsafe
- */
-func detectUnsafeCast(expr ast.Expr) (ast.Expr, ast.Expr, bool) {
-	// find the potential cast expression: it might be a star expression (pointer), then take the inner node. Otherwise
-	// we have the cast node directly
-	var castExpr ast.Expr
-	starExpr, ok := expr.(*ast.StarExpr)
-	if ok {
-		castExpr = starExpr.X
-	} else {
-		castExpr = expr
-	}
-
-	// check if the expression is a CallExpr, which is needed for it to be a cast
-	targetCastCallExpr, ok := castExpr.(*ast.CallExpr)
-	if !ok {
-		return nil, nil, false
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok {
+				if function, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func); ok {
+					addAnon(program.FuncValue(function))
+				}
+			}
+		}
 	}
-
-	// check if there are arguments to the call expression at all
-	if len(targetCastCallExpr.Args) == 0 {
-		return nil, nil, false
+	if init := ssaPkg.Func("init"); init != nil {
+		addAnon(init)
 	}
+	return functions
+}
 
-	// now, extract the source expression in the cast and check if it is a CallExpr too. This is necessary because we
-	// try to filter casts that involve an intermediate unsafe.Pointer step
-	sourceCastCallExpr, ok := targetCastCallExpr.Args[0].(*ast.CallExpr)
-	if !ok {
-		return nil, nil, false
+/**
+ * checks if a conversion is a misused cast from unsafe.Pointer to a pointer to a struct, slice, or array type, and
+ * returns messages describing the problems
+ */
+func checkStructCast(conversion *ssa.Convert, origins *pointerOrigins, archs []archSizes,
+	pass *analysis.Pass) []string {
+	// first, check if this is a conversion from unsafe.Pointer to another pointer type
+	if !resolve.IsUnsafePointerType(conversion.X.Type()) {
+		return nil
 	}
-
-	// extract the potential intermediate unsafe.Pointer step and check whether is is a selector expression
-	sourceCastUnsafePointerSelector, ok := sourceCastCallExpr.Fun.(*ast.SelectorExpr)
+	dstPointer, ok := conversion.Type().Underlying().(*types.Pointer)
 	if !ok {
-		return nil, nil, false
+		return nil
 	}
 
-	// now, check whether it is unsafe.Pointer indeed
-	sourceCastUnsafePointerSelectorX, ok := sourceCastUnsafePointerSelector.X.(*ast.Ident)
+	// it is a cast. Find out where the unsafe.Pointer came from, possibly through variables, struct fields and helper
+	// functions
+	src, ok := origins.pointee(conversion.X)
 	if !ok {
-		return nil, nil, false
-	}
-
-	if sourceCastUnsafePointerSelector.Sel.Name != "Pointer" || sourceCastUnsafePointerSelectorX.Name != "unsafe" {
-		return nil, nil, false
+		return nil
 	}
+	return checkCast(src, dstPointer, archs, pass)
+}
 
-	// we have an unsafe cast. Now, extract the source expression, and take care of a potential & operator
-	var sourceExpr ast.Expr
-	sourceUnary, ok := sourceCastCallExpr.Args[0].(*ast.UnaryExpr)
-	if ok && sourceUnary.Op == token.AND {
-		sourceExpr = sourceUnary.X
-	} else {
-		sourceExpr = sourceCastCallExpr.Args[0]
+/**
+ * checks if a cast of a pointer to a value to another pointer type is misused, and returns messages describing the
+ * problems
+ */
+func checkCast(src pointee, dstPointer *types.Pointer, archs []archSizes, pass *analysis.Pass) []string {
+	// the layout of types that depend on a type parameter is not known before they are instantiated
+	if resolve.ContainsTypeParam(src.typ) || resolve.ContainsTypeParam(dstPointer.Elem()) {
+		return nil
+	}
+
+	// get the source and destination types
+	srcType := src.typ.Underlying()
+	dstType := dstPointer.Elem().Underlying()
+	qualifier := types.RelativeTo(pass.Pkg)
+
+	// check if the cast reads beyond the end of the source value. This is only known if the source is the address of a
+	// variable, other pointers might point into a larger value
+	var messages []string
+	if src.exact {
+		if message, ok := checkOutOfBoundsCast(srcType, dstType, archs); ok {
+			messages = append(messages, message)
+		}
 	}
 
-	// extract the target expression, and take care of parenthesis and a star operator
-	targetParen, ok := targetCastCallExpr.Fun.(*ast.ParenExpr)
-	if !ok {
-		return nil, nil, false
+	// casts between slices or arrays reinterpret each element
+	if elementMessages, ok := checkElementsCast(srcType, dstType, archs, qualifier); ok {
+		return append(messages, elementMessages...)
 	}
 
-	targetStar, ok := targetParen.X.(*ast.StarExpr)
-	if !ok {
-		return nil, nil, false
+	// then, check if the types are structs with layouts that match on some architectures but not on others
+	if message, ok := checkIncompatibleStructsCast(srcType, dstType, archs, qualifier); ok {
+		messages = append(messages, message)
 	}
 
-	// finally, we know that this is a cast involving unsafe, return the source and destination
-	return sourceExpr, targetStar.X, true
-}
+	// and check if the cast hides pointers from the garbage collector or makes it treat other values as pointers
+	messages = append(messages, checkPointerBitmaps(srcType, dstType, archs, qualifier)...)
 
-/**
- * finds the type of an expression from the type information contained in the analysis pass
- */
-func getObjectType(expr ast.Expr, pass *analysis.Pass) types.Type {
-	return pass.TypesInfo.Types[expr].Type.Underlying()
+	return messages
 }
 
 /**
- * for two types, checks whether they are incompatible, platform-dependent types
+ * for two types, checks whether they are structs with layouts that are the same on some architectures but different on
+ * others, which means the cast only works by accident on some of them
  */
-func checkIncompatibleStructsCast(src types.Type, dst types.Type) bool {
+func checkIncompatibleStructsCast(src types.Type, dst types.Type, archs []archSizes,
+	qualifier types.Qualifier) (string, bool) {
 	// check if the source type is a struct
 	srcStruct, ok := src.(*types.Struct)
 	if !ok {
-		return false
+		return "", false
 	}
 
 	// check if the destination type is a struct
 	dstStruct, ok := dst.(*types.Struct)
 	if !ok {
-		return false
-	}
-
-	srcPlatformDependentCount := 0
-	dstPlatformDependentCount := 0
-
-	// count platform dependent types in the source type
-	for i := 0; i < srcStruct.NumFields(); i++ {
-		if isPlatformDependent(srcStruct.Field(i)) {
-			srcPlatformDependentCount += 1
+		return "", false
+	}
+
+	// compare the layouts on every architecture, and remember the first field where they differ on the first
+	// architecture with a difference
+	var differing []string
+	var description string
+	for _, arch := range archs {
+		srcLayout := layoutOf(srcStruct, arch.sizes)
+		dstLayout := layoutOf(dstStruct, arch.sizes)
+		i, diverges := firstDivergingField(srcLayout, dstLayout)
+		if !diverges {
+			continue
 		}
-	}
-
-	// count platform dependent types in the destination type
-	for i := 0; i < dstStruct.NumFields(); i++ {
-		if isPlatformDependent(dstStruct.Field(i)) {
-			dstPlatformDependentCount += 1
+		if differing == nil {
+			description = fmt.Sprintf("first diverging field on %s is %s in source and %s in destination", arch.arch,
+				describeField(srcLayout, i, qualifier), describeField(dstLayout, i, qualifier))
 		}
+		differing = append(differing, arch.arch)
 	}
 
-	// check whether the amounts match
-	return srcPlatformDependentCount != dstPlatformDependentCount
-}
-
-/**
- * checks whether a type is platform dependent
- */
-func isPlatformDependent(field *types.Var) bool {
-	// check whether it is a basic type
-	basicType, ok := field.Type().(*types.Basic)
-	if !ok {
-		return false
-	}
-
-	// check whether the basic type is int, uint, or uintptr
-	kind := basicType.Kind()
-
-	if kind == types.Int || kind == types.Uint || kind == types.Uintptr {
-		return true
+	// layouts that are the same everywhere are fine, and layouts that are different everywhere are not an architecture
+	// dependent problem
+	if len(differing) == 0 || len(differing) == len(archs) {
+		return "", false
 	}
 
-	return false
+	return fmt.Sprintf("unsafe cast between structs with layouts that differ on %s: %s",
+		strings.Join(differing, ", "), description), true
 }
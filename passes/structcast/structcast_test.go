@@ -11,10 +11,47 @@ func Test(t *testing.T) {
 	testdata := analysistest.TestData()
 	testPackages := []string{
 		"bad/architecture_sized_variable",
+		"bad/renamed_unsafe",
+		"bad/reordered_fields",
+		"bad/alignment_padding",
+		"bad/nested_struct",
+		"bad/embedded_struct",
+		"bad/array_field",
+		"bad/named_type",
+		"bad/pointer_shaped",
+		"bad/hidden_pointer",
+		"bad/misplaced_pointer",
+		"bad/larger_struct",
+		"bad/pointer_flow",
+		"bad/slice_reinterpretation",
+		"bad/type_errors",
 
 		"good/strictly_sized_struct",
 		"good/no_cast",
+		"good/shadowed_unsafe",
+		"good/explicit_padding",
+		"good/flattened_struct",
+		"good/word_components",
+		"good/matching_pointers",
+		"good/smaller_struct",
+		"good/unknown_origin",
+		"good/slice_reinterpretation",
+		"good/generic_struct",
 	}
 	analysistest.Run(t, testdata, structcast.Analyzer, testPackages...)
 }
 
+func TestArchitectures(t *testing.T) {
+	// compare layouts only on 64 bit architectures, where int and int64 are the same
+	setArchitectures(t, "amd64,arm64")
+	analysistest.Run(t, analysistest.TestData(), structcast.Analyzer, "good/configured_architectures")
+}
+
+func setArchitectures(t *testing.T, archs string) {
+	flag := structcast.Analyzer.Flags.Lookup("archs")
+	previous := flag.Value.String()
+	if err := flag.Value.Set(archs); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = flag.Value.Set(previous) })
+}
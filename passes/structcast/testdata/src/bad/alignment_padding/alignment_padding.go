@@ -0,0 +1,18 @@
+package alignment_padding
+
+import "unsafe"
+
+type PinkStruct struct {
+	A int32
+	B int64
+}
+
+type VioletStruct struct {
+	A int32
+	_ int32
+	B int64
+}
+
+func UnsafeCast(pink PinkStruct) VioletStruct {
+	return *(*VioletStruct)(unsafe.Pointer(&pink)) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is B int64 at offset 4 in source and B int64 at offset 8 in destination" "unsafe cast to a larger struct reads out of bounds: source is 12 bytes and destination is 16 bytes on 386, arm, mips"
+}
@@ -21,7 +21,7 @@ func UnsafeCast() {
 		C: 9000,
 	}
 
-	violet := *(*VioletStruct)(unsafe.Pointer(&pink)) // want "unsafe cast between structs with mismatching count of platform dependent field sizes"
+	violet := *(*VioletStruct)(unsafe.Pointer(&pink)) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is B int at offset 4 in source and B int64 at offset 4 in destination" "unsafe cast to a larger struct reads out of bounds: source is 16 bytes and destination is 20 bytes on 386, arm, mips"
 
 	_ = violet // ok
 }
\ No newline at end of file
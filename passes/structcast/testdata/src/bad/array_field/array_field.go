@@ -0,0 +1,16 @@
+package array_field
+
+import "unsafe"
+
+type PinkStruct struct {
+	Values [2]int
+}
+
+type VioletStruct struct {
+	A int64
+	B int64
+}
+
+func UnsafeCast(pink PinkStruct) VioletStruct {
+	return *(*VioletStruct)(unsafe.Pointer(&pink)) // want `unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is Values\[0\] int at offset 0 in source and A int64 at offset 0 in destination` "unsafe cast to a larger struct reads out of bounds: source is 8 bytes and destination is 16 bytes on 386, arm, mips"
+}
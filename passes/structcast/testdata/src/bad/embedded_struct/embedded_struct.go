@@ -0,0 +1,23 @@
+package embedded_struct
+
+import "unsafe"
+
+type Base struct {
+	A int32
+	B int
+}
+
+type PinkStruct struct {
+	Base
+	C int64
+}
+
+type VioletStruct struct {
+	A int32
+	B int64
+	C int64
+}
+
+func UnsafeCast(pink PinkStruct) VioletStruct {
+	return *(*VioletStruct)(unsafe.Pointer(&pink)) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is Base.B int at offset 4 in source and B int64 at offset 4 in destination" "unsafe cast to a larger struct reads out of bounds: source is 16 bytes and destination is 20 bytes on 386, arm, mips"
+}
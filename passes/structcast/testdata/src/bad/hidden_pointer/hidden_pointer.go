@@ -0,0 +1,21 @@
+package hidden_pointer
+
+import "unsafe"
+
+type Node struct {
+	Next  *Node
+	Value int
+}
+
+type RawNode struct {
+	Next  uintptr
+	Value int
+}
+
+func HidePointer(node Node) RawNode {
+	return *(*RawNode)(unsafe.Pointer(&node)) // want "unsafe cast between structs hides a pointer from the garbage collector on 386, amd64, arm, arm64, mips, wasm: Next \\*Node in source overlaps Next uintptr in destination at offset 0"
+}
+
+func FabricatePointer(raw RawNode) Node {
+	return *(*Node)(unsafe.Pointer(&raw)) // want "unsafe cast between structs makes the garbage collector treat a non-pointer value as a pointer on 386, amd64, arm, arm64, mips, wasm: Next uintptr in source overlaps Next \\*Node in destination at offset 0"
+}
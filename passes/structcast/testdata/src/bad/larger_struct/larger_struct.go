@@ -0,0 +1,33 @@
+package larger_struct
+
+import "unsafe"
+
+type Small struct {
+	A int32
+}
+
+type Large struct {
+	A int32
+	B int32
+}
+
+type Words struct {
+	A int64
+	B int64
+}
+
+type Word struct {
+	A int
+}
+
+func ReadLarger(small Small) Large {
+	return *(*Large)(unsafe.Pointer(&small)) // want "unsafe cast to a larger struct reads out of bounds: source is 4 bytes and destination is 8 bytes on 386, amd64, arm, arm64, mips, wasm"
+}
+
+func ReadFromArray(buf [4]byte) *Large {
+	return (*Large)(unsafe.Pointer(&buf)) // want "unsafe cast to a larger struct reads out of bounds: source is 4 bytes and destination is 8 bytes on 386, amd64, arm, arm64, mips, wasm"
+}
+
+func ReadLargerPerArchitecture(word Word) Words {
+	return *(*Words)(unsafe.Pointer(&word)) // want "unsafe cast to a larger struct reads out of bounds: source is 4 bytes and destination is 16 bytes on 386, arm, mips; source is 8 bytes and destination is 16 bytes on amd64, arm64, wasm"
+}
@@ -0,0 +1,19 @@
+package misplaced_pointer
+
+import "unsafe"
+
+type PinkStruct struct {
+	A int32
+	B int32
+	P *int
+}
+
+type VioletStruct struct {
+	A int32
+	P *int
+	B int32
+}
+
+func UnsafeCast(pink PinkStruct) VioletStruct {
+	return *(*VioletStruct)(unsafe.Pointer(&pink)) // want "unsafe cast between structs with layouts that differ on amd64, arm64, wasm: first diverging field on amd64 is B int32 at offset 4 in source and P \\*int at offset 8 in destination" "unsafe cast between structs hides a pointer from the garbage collector on 386, arm, mips: P \\*int in source overlaps B int32 in destination at offset 8" "unsafe cast between structs makes the garbage collector treat a non-pointer value as a pointer on 386, arm, mips: B int32 in source overlaps P \\*int in destination at offset 4" "unsafe cast to a larger struct reads out of bounds: source is 16 bytes and destination is 24 bytes on amd64, arm64, wasm"
+}
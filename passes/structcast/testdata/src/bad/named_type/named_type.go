@@ -0,0 +1,19 @@
+package named_type
+
+import "unsafe"
+
+type Count int
+
+type PinkStruct struct {
+	N Count
+	M int64
+}
+
+type VioletStruct struct {
+	N int64
+	M int64
+}
+
+func UnsafeCast(pink PinkStruct) VioletStruct {
+	return *(*VioletStruct)(unsafe.Pointer(&pink)) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is N Count at offset 0 in source and N int64 at offset 0 in destination" "unsafe cast to a larger struct reads out of bounds: source is 12 bytes and destination is 16 bytes on 386, arm, mips"
+}
@@ -0,0 +1,19 @@
+package nested_struct
+
+import "unsafe"
+
+type PinkStruct struct {
+	Inner struct {
+		A int32
+		B int64
+	}
+}
+
+type VioletStruct struct {
+	A int32
+	B int
+}
+
+func UnsafeCast(pink PinkStruct) VioletStruct {
+	return *(*VioletStruct)(unsafe.Pointer(&pink)) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is Inner.B int64 at offset 4 in source and B int at offset 4 in destination"
+}
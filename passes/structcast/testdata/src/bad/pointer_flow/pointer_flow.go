@@ -0,0 +1,75 @@
+package pointer_flow
+
+import "unsafe"
+
+type PinkStruct struct {
+	A uint8
+	B int
+	C int64
+}
+
+type VioletStruct struct {
+	A uint8
+	B int64
+	C int64
+}
+
+type holder struct {
+	p unsafe.Pointer
+}
+
+var global unsafe.Pointer
+
+func ThroughVariables(pink PinkStruct) VioletStruct {
+	p := unsafe.Pointer(&pink)
+	q := p
+	return *(*VioletStruct)(q) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is B int at offset 4 in source and B int64 at offset 4 in destination" "unsafe cast to a larger struct reads out of bounds: source is 16 bytes and destination is 20 bytes on 386, arm, mips"
+}
+
+func ThroughPointer(pink *PinkStruct) *VioletStruct {
+	return (*VioletStruct)(unsafe.Pointer(pink)) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is B int at offset 4 in source and B int64 at offset 4 in destination"
+}
+
+func ThroughBranches(pink, other *PinkStruct, first bool) *VioletStruct {
+	var p unsafe.Pointer
+	if first {
+		p = unsafe.Pointer(pink)
+	} else {
+		p = unsafe.Pointer(other)
+	}
+	return (*VioletStruct)(p) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is B int at offset 4 in source and B int64 at offset 4 in destination"
+}
+
+func ThroughAddressedVariable(pink *PinkStruct) *VioletStruct {
+	var p unsafe.Pointer
+	pp := &p
+	*pp = unsafe.Pointer(pink)
+	return (*VioletStruct)(*pp) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is B int at offset 4 in source and B int64 at offset 4 in destination"
+}
+
+func newHolder(pink *PinkStruct) holder {
+	return holder{p: unsafe.Pointer(pink)}
+}
+
+func ThroughStructField(pink *PinkStruct) *VioletStruct {
+	h := newHolder(pink)
+	return (*VioletStruct)(h.p) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is B int at offset 4 in source and B int64 at offset 4 in destination"
+}
+
+func ThroughGlobal(pink *PinkStruct) *VioletStruct {
+	global = unsafe.Pointer(pink)
+	return (*VioletStruct)(global) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is B int at offset 4 in source and B int64 at offset 4 in destination"
+}
+
+func pointerOf(pink *PinkStruct) unsafe.Pointer {
+	return unsafe.Pointer(pink)
+}
+
+func forward(p unsafe.Pointer) (unsafe.Pointer, bool) {
+	return p, p != nil
+}
+
+func ThroughHelpers(pink PinkStruct) VioletStruct {
+	p, _ := forward(pointerOf(&pink))
+	return *(*VioletStruct)(p) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is B int at offset 4 in source and B int64 at offset 4 in destination" "unsafe cast to a larger struct reads out of bounds: source is 16 bytes and destination is 20 bytes on 386, arm, mips"
+}
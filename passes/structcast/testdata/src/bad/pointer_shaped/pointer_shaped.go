@@ -0,0 +1,64 @@
+package pointer_shaped
+
+import "unsafe"
+
+type PointerStruct struct {
+	P *int
+	N int64
+}
+
+type MapChanFuncStruct struct {
+	M map[int]int
+	C chan int
+	F func()
+}
+
+type StringStruct struct {
+	S string
+	N int64
+}
+
+type SliceStruct struct {
+	B []byte
+}
+
+type InterfaceStruct struct {
+	I interface{}
+}
+
+type TwoWords struct {
+	A int64
+	B int64
+}
+
+type ThreeWords struct {
+	A int64
+	B int64
+	C int64
+}
+
+type StringWords struct {
+	P uintptr
+	L int64
+	N int64
+}
+
+func CastPointer(s PointerStruct) TwoWords {
+	return *(*TwoWords)(unsafe.Pointer(&s)) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is P \\*int at offset 0 in source and A int64 at offset 0 in destination" "unsafe cast between structs hides a pointer from the garbage collector on 386, amd64, arm, arm64, mips, wasm: P \\*int in source overlaps A int64 in destination at offset 0" "unsafe cast to a larger struct reads out of bounds: source is 12 bytes and destination is 16 bytes on 386, arm, mips"
+}
+
+func CastMapChanFunc(s MapChanFuncStruct) ThreeWords {
+	return *(*ThreeWords)(unsafe.Pointer(&s)) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is M map\\[int\\]int at offset 0 in source and A int64 at offset 0 in destination" "unsafe cast between structs hides a pointer from the garbage collector on 386, amd64, arm, arm64, mips, wasm: M map\\[int\\]int in source overlaps A int64 in destination at offset 0" "unsafe cast to a larger struct reads out of bounds: source is 12 bytes and destination is 24 bytes on 386, arm, mips"
+}
+
+func CastString(s StringStruct) StringWords {
+	return *(*StringWords)(unsafe.Pointer(&s)) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is S.len int at offset 4 in source and L int64 at offset 4 in destination" "unsafe cast between structs hides a pointer from the garbage collector on 386, amd64, arm, arm64, mips, wasm: S.data unsafe.Pointer in source overlaps P uintptr in destination at offset 0" "unsafe cast to a larger struct reads out of bounds: source is 16 bytes and destination is 20 bytes on 386, arm, mips"
+}
+
+func CastSlice(s SliceStruct) ThreeWords {
+	return *(*ThreeWords)(unsafe.Pointer(&s)) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is B.data unsafe.Pointer at offset 0 in source and A int64 at offset 0 in destination" "unsafe cast between structs hides a pointer from the garbage collector on 386, amd64, arm, arm64, mips, wasm: B.data unsafe.Pointer in source overlaps A int64 in destination at offset 0" "unsafe cast to a larger struct reads out of bounds: source is 12 bytes and destination is 24 bytes on 386, arm, mips"
+}
+
+func CastInterface(s InterfaceStruct) TwoWords {
+	return *(*TwoWords)(unsafe.Pointer(&s)) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is I.type unsafe.Pointer at offset 0 in source and A int64 at offset 0 in destination" "unsafe cast between structs hides a pointer from the garbage collector on 386, amd64, arm, arm64, mips, wasm: I.type unsafe.Pointer in source overlaps A int64 in destination at offset 0" "unsafe cast to a larger struct reads out of bounds: source is 8 bytes and destination is 16 bytes on 386, arm, mips"
+}
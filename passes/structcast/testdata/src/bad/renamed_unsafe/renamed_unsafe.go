@@ -0,0 +1,26 @@
+package renamed_unsafe
+
+import (
+	u "unsafe"
+	. "unsafe"
+)
+
+type PinkStruct struct {
+	A uint8
+	B int
+	C int64
+}
+
+type VioletStruct struct {
+	A uint8
+	B int64
+	C int64
+}
+
+func RenamedImport(pink PinkStruct) VioletStruct {
+	return *(*VioletStruct)(u.Pointer(&pink)) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is B int at offset 4 in source and B int64 at offset 4 in destination" "unsafe cast to a larger struct reads out of bounds: source is 16 bytes and destination is 20 bytes on 386, arm, mips"
+}
+
+func DotImport(pink PinkStruct) VioletStruct {
+	return *(*VioletStruct)(Pointer(&pink)) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is B int at offset 4 in source and B int64 at offset 4 in destination" "unsafe cast to a larger struct reads out of bounds: source is 16 bytes and destination is 20 bytes on 386, arm, mips"
+}
@@ -0,0 +1,17 @@
+package reordered_fields
+
+import "unsafe"
+
+type PinkStruct struct {
+	P uintptr
+	N int64
+}
+
+type VioletStruct struct {
+	N int64
+	P uintptr
+}
+
+func UnsafeCast(pink PinkStruct) VioletStruct {
+	return *(*VioletStruct)(unsafe.Pointer(&pink)) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is P uintptr at offset 0 in source and N int64 at offset 0 in destination"
+}
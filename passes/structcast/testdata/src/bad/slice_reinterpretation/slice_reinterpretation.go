@@ -0,0 +1,48 @@
+package slice_reinterpretation
+
+import "unsafe"
+
+type Pair struct {
+	A int32
+	B int32
+}
+
+type Wide struct {
+	A int64
+	B int64
+}
+
+type Mixed struct {
+	A int32
+	B int
+}
+
+type Fixed struct {
+	A int32
+	B int64
+}
+
+func CastSlice(pairs []Pair) []Wide {
+	return *(*[]Wide)(unsafe.Pointer(&pairs)) // want "unsafe cast between slices keeps the length although the element sizes differ: source element Pair is 8 bytes and destination element Wide is 16 bytes on 386, amd64, arm, arm64, mips, wasm"
+}
+
+func CastBytes(b []byte) []uint32 {
+	return *(*[]uint32)(unsafe.Pointer(&b)) // want "unsafe cast between slices keeps the length although the element sizes differ: source element byte is 1 bytes and destination element uint32 is 4 bytes on 386, amd64, arm, arm64, mips, wasm"
+}
+
+func CastSliceLayout(mixed []Mixed) []Fixed {
+	return *(*[]Fixed)(unsafe.Pointer(&mixed)) // want "unsafe cast between slices keeps the length although the element sizes differ: source element Mixed is 8 bytes and destination element Fixed is 12 bytes on 386, arm, mips" "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is B int at offset 4 in source and B int64 at offset 4 in destination"
+}
+
+func CastArray(mixed [2]Mixed) *[2]Fixed {
+	return (*[2]Fixed)(unsafe.Pointer(&mixed)) // want "unsafe cast to a larger array reads out of bounds: source is 16 bytes and destination is 24 bytes on 386, arm, mips" "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is B int at offset 4 in source and B int64 at offset 4 in destination"
+}
+
+func UnsafeSlice(pairs []Pair) []Wide {
+	return unsafe.Slice((*Wide)(unsafe.Pointer(&pairs[0])), len(pairs)) // want "unsafe.Slice length is not scaled by the element size ratio: source element Pair is 8 bytes and destination element Wide is 16 bytes on 386, amd64, arm, arm64, mips, wasm"
+}
+
+func UnsafeSliceBytes(b []byte) []uint64 {
+	p := (*uint64)(unsafe.Pointer(&b[0]))
+	return unsafe.Slice(p, cap(b)) // want "unsafe.Slice length is not scaled by the element size ratio: source element byte is 1 bytes and destination element uint64 is 8 bytes on 386, amd64, arm, arm64, mips, wasm"
+}
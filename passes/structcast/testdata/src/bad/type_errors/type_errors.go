@@ -0,0 +1,21 @@
+package type_errors
+
+import "unsafe"
+
+type PinkStruct struct {
+	A uint8
+	B int
+	C int64
+}
+
+type VioletStruct struct {
+	A uint8
+	B int64
+	C int64
+}
+
+func UnsafeCast(pink *PinkStruct) int64 {
+	// the package does not type check, so only direct casts are found
+	violet := (*VioletStruct)(unsafe.Pointer(pink)) // want "unsafe cast between structs with layouts that differ on 386, arm, mips: first diverging field on 386 is B int at offset 4 in source and B int64 at offset 4 in destination"
+	return violet.C + undefined
+}
@@ -0,0 +1,19 @@
+package configured_architectures
+
+import "unsafe"
+
+type PinkStruct struct {
+	A uint8
+	B int
+	C int64
+}
+
+type VioletStruct struct {
+	A uint8
+	B int64
+	C int64
+}
+
+func UnsafeCast(pink PinkStruct) VioletStruct {
+	return *(*VioletStruct)(unsafe.Pointer(&pink)) // ok
+}
@@ -0,0 +1,19 @@
+package explicit_padding
+
+import "unsafe"
+
+type PinkStruct struct {
+	A int32
+	_ [4]byte
+	B int64
+}
+
+type VioletStruct struct {
+	A int32
+	_ int32
+	B int64
+}
+
+func UnsafeCast(pink PinkStruct) VioletStruct {
+	return *(*VioletStruct)(unsafe.Pointer(&pink)) // ok
+}
@@ -0,0 +1,27 @@
+package flattened_struct
+
+import "unsafe"
+
+type Point struct {
+	X int32
+	Y int32
+}
+
+type PinkStruct struct {
+	Point
+	Inner struct {
+		Z int64
+	}
+	Values [2]uint16
+}
+
+type VioletStruct struct {
+	X      int32
+	Y      int32
+	Z      int64
+	V0, V1 uint16
+}
+
+func UnsafeCast(pink PinkStruct) VioletStruct {
+	return *(*VioletStruct)(unsafe.Pointer(&pink)) // ok
+}
@@ -0,0 +1,34 @@
+package generic_struct
+
+import "unsafe"
+
+type Pair[T any] struct {
+	First  T
+	Second int
+}
+
+type Plain struct {
+	First  int64
+	Second int
+}
+
+func FromPlain[T any](p *Plain) *Pair[T] {
+	// the layout of Pair[T] is only known once T is instantiated
+	return (*Pair[T])(unsafe.Pointer(p))
+}
+
+func ToPlain[T any](pair Pair[T]) *Plain {
+	return (*Plain)(unsafe.Pointer(&pair))
+}
+
+func Elements[T any](values []T) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(&values[0])), len(values))
+}
+
+func Reinterpret[S, D any](values []S) []D {
+	return *(*[]D)(unsafe.Pointer(&values))
+}
+
+func Array[T any](value *[4]T) *[2]int64 {
+	return (*[2]int64)(unsafe.Pointer(value))
+}
@@ -0,0 +1,20 @@
+package matching_pointers
+
+import "unsafe"
+
+type PinkStruct struct {
+	Next  *PinkStruct
+	Name  string
+	Items map[string]int
+}
+
+type VioletStruct struct {
+	Next    unsafe.Pointer
+	NameRaw unsafe.Pointer
+	NameLen int
+	Items   unsafe.Pointer
+}
+
+func UnsafeCast(pink PinkStruct) VioletStruct {
+	return *(*VioletStruct)(unsafe.Pointer(&pink)) // ok
+}
@@ -0,0 +1,13 @@
+package shadowed_unsafe
+
+import "good/shadowed_unsafe/unsafe"
+
+type PinkStruct struct {
+	A uint8
+	B int
+	C int64
+}
+
+func NotUnsafe(pink PinkStruct) unsafe.VioletStruct {
+	return *(*unsafe.VioletStruct)(unsafe.Pointer(&pink)) // ok
+}
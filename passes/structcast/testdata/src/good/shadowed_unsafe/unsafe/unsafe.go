@@ -0,0 +1,12 @@
+package unsafe
+
+type VioletStruct struct {
+	A uint8
+	B int64
+	C int64
+}
+
+// Pointer has the same name as unsafe.Pointer, but it is an ordinary function that returns a new struct
+func Pointer(p interface{}) *VioletStruct {
+	return &VioletStruct{}
+}
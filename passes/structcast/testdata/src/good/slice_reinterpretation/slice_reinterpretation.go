@@ -0,0 +1,38 @@
+package slice_reinterpretation
+
+import "unsafe"
+
+type Pair struct {
+	A int32
+	B int32
+}
+
+type Swapped struct {
+	B int32
+	A int32
+}
+
+type Wide struct {
+	A int64
+	B int64
+}
+
+func CastSameSize(pairs []Pair) []Swapped {
+	return *(*[]Swapped)(unsafe.Pointer(&pairs)) // ok
+}
+
+func CastSameSizeArray(pairs [4]Pair) *[4]Swapped {
+	return (*[4]Swapped)(unsafe.Pointer(&pairs)) // ok
+}
+
+func UnsafeSliceScaled(pairs []Pair) []Wide {
+	return unsafe.Slice((*Wide)(unsafe.Pointer(&pairs[0])), len(pairs)/2) // ok
+}
+
+func UnsafeSliceBytes(b []byte) []uint32 {
+	return unsafe.Slice((*uint32)(unsafe.Pointer(&b[0])), len(b)/4) // ok
+}
+
+func UnsafeSliceSameSize(pairs []Pair) []Swapped {
+	return unsafe.Slice((*Swapped)(unsafe.Pointer(&pairs[0])), len(pairs)) // ok
+}
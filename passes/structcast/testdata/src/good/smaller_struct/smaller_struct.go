@@ -0,0 +1,24 @@
+package smaller_struct
+
+import "unsafe"
+
+type Small struct {
+	A int32
+}
+
+type Large struct {
+	A int32
+	B int32
+}
+
+func ReadPrefix(large Large) Small {
+	return *(*Small)(unsafe.Pointer(&large)) // ok
+}
+
+func ReadFromBuffer(buf []byte) *Large {
+	return (*Large)(unsafe.Pointer(&buf[0])) // ok
+}
+
+func ReadFromElement(items []Small) *Large {
+	return (*Large)(unsafe.Pointer(&items[0])) // ok
+}
@@ -0,0 +1,35 @@
+package unknown_origin
+
+import "unsafe"
+
+type PinkStruct struct {
+	A uint8
+	B int
+	C int64
+}
+
+type VioletStruct struct {
+	A uint8
+	B int64
+	C int64
+}
+
+func FromParameter(p unsafe.Pointer) *VioletStruct {
+	return (*VioletStruct)(p) // ok
+}
+
+func FromDifferentTypes(pink *PinkStruct, violet *VioletStruct, first bool) *VioletStruct {
+	p := unsafe.Pointer(violet)
+	if first {
+		p = unsafe.Pointer(pink)
+	}
+	return (*VioletStruct)(p) // ok
+}
+
+func forward(p unsafe.Pointer) unsafe.Pointer {
+	return p
+}
+
+func ThroughHelper(violet *VioletStruct) *VioletStruct {
+	return (*VioletStruct)(forward(unsafe.Pointer(violet))) // ok
+}
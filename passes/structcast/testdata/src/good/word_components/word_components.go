@@ -0,0 +1,23 @@
+package word_components
+
+import "unsafe"
+
+type PinkStruct struct {
+	S string
+	B []byte
+	I interface{}
+}
+
+type VioletStruct struct {
+	StringData  unsafe.Pointer
+	StringLen   int
+	SliceData   unsafe.Pointer
+	SliceLen    int
+	SliceCap    int
+	Interface   unsafe.Pointer
+	InterfaceOf *int
+}
+
+func UnsafeCast(pink PinkStruct) VioletStruct {
+	return *(*VioletStruct)(unsafe.Pointer(&pink)) // ok
+}
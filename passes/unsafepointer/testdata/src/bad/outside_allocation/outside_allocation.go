@@ -0,0 +1,25 @@
+package outside_allocation
+
+import (
+	"unsafe"
+)
+
+type T struct {
+	A int32
+	B int32
+}
+
+func EndPointer() unsafe.Pointer {
+	var x T
+	return unsafe.Pointer(uintptr(unsafe.Pointer(&x)) + unsafe.Sizeof(x)) // want "pointer arithmetic moves outside the allocation of x: offset 8 is not within its 8 bytes"
+}
+
+func BeforeStart() *int32 {
+	var values [4]int32
+	return (*int32)(unsafe.Pointer(uintptr(unsafe.Pointer(&values)) - 4)) // want "pointer arithmetic moves outside the allocation of values: offset -4 is not within its 16 bytes"
+}
+
+func PastLastElement() *int32 {
+	var values [4]int32
+	return (*int32)(unsafe.Pointer(uintptr(unsafe.Pointer(&values)) + 2*unsafe.Sizeof(values[0]) + 8)) // want "pointer arithmetic moves outside the allocation of values: offset 16 is not within its 16 bytes"
+}
@@ -0,0 +1,17 @@
+package reflect_value
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+func StoredPointer(s []byte) *byte {
+	address := reflect.ValueOf(s).Pointer()
+	return (*byte)(unsafe.Pointer(address)) // want "the result of reflect.Value.Pointer must be converted to unsafe.Pointer immediately, not stored in a variable first"
+}
+
+func StoredUnsafeAddr(x *int) *int {
+	var address uintptr
+	address = reflect.ValueOf(x).Elem().UnsafeAddr()
+	return (*int)(unsafe.Pointer(address)) // want "the result of reflect.Value.UnsafeAddr must be converted to unsafe.Pointer immediately, not stored in a variable first"
+}
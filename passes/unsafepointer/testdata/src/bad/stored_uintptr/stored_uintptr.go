@@ -0,0 +1,33 @@
+package stored_uintptr
+
+import (
+	"unsafe"
+)
+
+type T struct {
+	A int64
+	B int64
+}
+
+func StoredBeforeConversion(t *T) *int64 {
+	address := uintptr(unsafe.Pointer(t))
+	offset := unsafe.Offsetof(t.B)
+	return (*int64)(unsafe.Pointer(address + offset)) // want "converting a uintptr stored in variable address to unsafe.Pointer is invalid, because the object it points to may have been moved or freed"
+}
+
+func StoredArithmetic(t *T) *int64 {
+	end := uintptr(unsafe.Pointer(t)) + unsafe.Offsetof(t.B)
+	return (*int64)(unsafe.Pointer(end)) // want "converting a uintptr stored in variable end to unsafe.Pointer is invalid, because the object it points to may have been moved or freed"
+}
+
+func ArbitraryValue(address uintptr) *T {
+	return (*T)(unsafe.Pointer(address)) // want "converting a uintptr stored in variable address to unsafe.Pointer is invalid, because the object it points to may have been moved or freed"
+}
+
+func lookupAddress() uintptr {
+	return 0x1000
+}
+
+func ReturnedValue() *T {
+	return (*T)(unsafe.Pointer(lookupAddress())) // want "converting an arbitrary uintptr value to unsafe.Pointer is invalid"
+}
@@ -0,0 +1,11 @@
+package syscall_argument
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+func StoredArgument(buffer []byte) {
+	address := uintptr(unsafe.Pointer(&buffer[0]))
+	syscall.Syscall(syscall.SYS_WRITE, 1, address, uintptr(len(buffer))) // want "uintptr\\(unsafe.Pointer\\(...\\)\\) must be passed to Syscall directly, not stored in a variable first"
+}
@@ -0,0 +1,81 @@
+package documented_patterns
+
+import (
+	"fmt"
+	"reflect"
+	"syscall"
+	"unsafe"
+)
+
+type T struct {
+	A int32
+	B int32
+}
+
+// pattern 1: conversion of *T1 to Pointer to *T2
+func PointerToPointer(f *float64) uint64 {
+	return *(*uint64)(unsafe.Pointer(f))
+}
+
+// pattern 2: conversion of a Pointer to a uintptr, but not back
+func PointerToUintptr(t *T) {
+	fmt.Printf("%x\n", uintptr(unsafe.Pointer(t)))
+}
+
+// pattern 3: conversion of a Pointer to a uintptr and back, with arithmetic
+func PointerArithmetic(t *T) *int32 {
+	return (*int32)(unsafe.Pointer(uintptr(unsafe.Pointer(t)) + unsafe.Offsetof(t.B)))
+}
+
+func ElementArithmetic(values *[4]int32, i int) *int32 {
+	return (*int32)(unsafe.Pointer(uintptr(unsafe.Pointer(values)) + uintptr(i)*unsafe.Sizeof(values[0])))
+}
+
+func LastElement() *int32 {
+	var values [4]int32
+	return (*int32)(unsafe.Pointer(uintptr(unsafe.Pointer(&values)) + 3*unsafe.Sizeof(values[0])))
+}
+
+func VariableBase(p unsafe.Pointer) *int32 {
+	// the pointer is not a conversion, so the size of its allocation is unknown
+	return (*int32)(unsafe.Pointer(uintptr(p) + 4))
+}
+
+func RoundTrip(t *T) *T {
+	return (*T)(unsafe.Pointer(uintptr(unsafe.Pointer(t))))
+}
+
+// pattern 4: conversion of a Pointer to a uintptr when calling syscall.Syscall
+func SyscallArgument(buffer []byte) {
+	syscall.Syscall(syscall.SYS_WRITE, 1, uintptr(unsafe.Pointer(&buffer[0])), uintptr(len(buffer)))
+}
+
+// pattern 5: conversion of the result of reflect.Value.Pointer or reflect.Value.UnsafeAddr from uintptr to Pointer
+func ReflectValuePointer() *int {
+	return (*int)(unsafe.Pointer(reflect.ValueOf(new(int)).Pointer()))
+}
+
+// pattern 6: conversion of a reflect.SliceHeader or reflect.StringHeader Data field to or from Pointer
+func ReflectHeaderData(s string, p *byte, n int) {
+	hdr := (*reflect.StringHeader)(unsafe.Pointer(&s))
+	fmt.Println(*(*byte)(unsafe.Pointer(hdr.Data)))
+	hdr.Data = uintptr(unsafe.Pointer(p))
+	hdr.Len = n
+}
+
+func ReflectHeaderLiteral(p *byte, n int) reflect.SliceHeader {
+	return reflect.SliceHeader{Data: uintptr(unsafe.Pointer(p)), Len: n, Cap: n}
+}
+
+type MysteryType reflect.SliceHeader
+
+func LookAlikeHeaderData(b []byte) *byte {
+	// look-alike headers have the same Data field as reflect.SliceHeader
+	hdr := (*MysteryType)(unsafe.Pointer(&b))
+	return (*byte)(unsafe.Pointer(hdr.Data))
+}
+
+func GenericArithmetic[T any](x T) unsafe.Pointer {
+	// the size of a type parameter is only known once it is instantiated
+	return unsafe.Pointer(uintptr(unsafe.Pointer(&x)) + 1)
+}
@@ -0,0 +1,455 @@
+package unsafepointer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/jlauinger/go-safer/passes/internal/resolve"
+)
+
+// Analyzer is a golang.org/x/tools/go/analysis style linter pass.
+// Use this with the Vet-style infrastructure.
+var Analyzer = &analysis.Analyzer{
+	Name:             "unsafepointer",
+	Doc:              "reports conversions between unsafe.Pointer and uintptr that follow none of the valid patterns",
+	Run:              run,
+	Requires:         []*analysis.Analyzer{inspect.Analyzer},
+	RunDespiteErrors: true,
+	ResultType:       reflect.TypeOf(Conversions{}),
+}
+
+// Pattern is one of the valid patterns for using unsafe.Pointer that are documented in the unsafe package
+type Pattern int
+
+const (
+	// Invalid conversions follow none of the patterns
+	Invalid Pattern = iota
+	// PointerToPointer is the conversion of a *T1 to Pointer to *T2
+	PointerToPointer
+	// PointerToUintptr is the conversion of a Pointer to a uintptr, but not back
+	PointerToUintptr
+	// PointerArithmetic is the conversion of a Pointer to a uintptr and back, with arithmetic in the same expression
+	PointerArithmetic
+	// SyscallArgument is the conversion of a Pointer to a uintptr when calling syscall.Syscall
+	SyscallArgument
+	// ReflectValuePointer is the conversion of the result of reflect.Value.Pointer or reflect.Value.UnsafeAddr
+	ReflectValuePointer
+	// ReflectHeaderData is the conversion of a reflect.SliceHeader or reflect.StringHeader Data field to or from Pointer
+	ReflectHeaderData
+)
+
+// Conversions maps the conversions between unsafe.Pointer and uintptr or other pointer types in a package to the
+// pattern they follow. It is the result of the analysis pass
+type Conversions map[*ast.CallExpr]Pattern
+
+/**
+ * run is the entry point to the analysis pass
+ */
+func run(pass *analysis.Pass) (interface{}, error) {
+	// get results from required inspect analyzer
+	inspectResult := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	// remember the values assigned to uintptr variables, to explain why converting them back is invalid
+	values := uintptrValues(inspectResult, pass)
+
+	conversions := make(Conversions)
+	inspectResult.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		node := n.(*ast.CallExpr)
+
+		// uintptr values created from pointers must not be stored before they are passed to a system call
+		if isSyscall(node, pass) {
+			for _, arg := range node.Args {
+				if variable := variableOf(arg, pass); variable != nil && anyValue(values[variable], func(value ast.Expr) bool {
+					return isPointerToUintptr(value, pass)
+				}) {
+					pass.Reportf(arg.Pos(), "uintptr(unsafe.Pointer(...)) must be passed to %s directly, not stored in "+
+						"a variable first", calleeName(node, pass))
+				}
+			}
+		}
+
+		// classify conversions between unsafe.Pointer and other types
+		pattern, ok := classify(node, stack, values, pass)
+		if !ok {
+			return true
+		}
+		conversions[node] = pattern
+		return true
+	})
+
+	return conversions, nil
+}
+
+/**
+ * classifies a call expression into one of the patterns if it is a conversion to or from unsafe.Pointer, and reports
+ * invalid conversions. The second return value is false if the call is not such a conversion
+ */
+func classify(call *ast.CallExpr, stack []ast.Node, values map[types.Object][]ast.Expr,
+	pass *analysis.Pass) (Pattern, bool) {
+	// check that the call is a conversion
+	target, ok := pass.TypesInfo.Types[call.Fun]
+	if !ok || !target.IsType() || len(call.Args) != 1 {
+		return Invalid, false
+	}
+	source := pass.TypesInfo.TypeOf(call.Args[0])
+	if source == nil {
+		return Invalid, false
+	}
+
+	switch {
+	case resolve.IsUnsafePointerType(target.Type) && isPointer(source),
+		isPointer(target.Type) && resolve.IsUnsafePointerType(source):
+		// conversions between unsafe.Pointer and other pointers are always pattern 1
+		return PointerToPointer, true
+	case isUintptr(target.Type) && resolve.IsUnsafePointerType(source):
+		// conversions to uintptr are valid in any context, but the context decides the pattern
+		return pointerToUintptrPattern(stack, pass), true
+	case resolve.IsUnsafePointerType(target.Type) && isUintptr(source):
+		// conversions back to unsafe.Pointer are only valid with a uintptr from a few sources
+		pattern := uintptrSourcePattern(call.Args[0], pass)
+		if pattern == Invalid {
+			pass.Reportf(call.Pos(), "%s", invalidConversionMessage(call.Args[0], values, pass))
+		} else if pattern == PointerArithmetic {
+			checkArithmeticBounds(call, pass)
+		}
+		return pattern, true
+	}
+	return Invalid, false
+}
+
+/**
+ * finds the pattern that a conversion from unsafe.Pointer to uintptr follows, given the AST nodes enclosing it
+ */
+func pointerToUintptrPattern(stack []ast.Node, pass *analysis.Pass) Pattern {
+	// go up through parentheses and arithmetic
+	i := len(stack) - 2
+	child := stack[len(stack)-1]
+	arithmetic := false
+	for ; i >= 0; i-- {
+		if _, ok := stack[i].(*ast.ParenExpr); ok {
+			child = stack[i]
+			continue
+		}
+		if binary, ok := stack[i].(*ast.BinaryExpr); ok && isArithmetic(binary.Op) {
+			arithmetic = true
+			child = stack[i]
+			continue
+		}
+		break
+	}
+	if i < 0 {
+		return PointerToUintptr
+	}
+
+	switch parent := stack[i].(type) {
+	case *ast.CallExpr:
+		// converted back to unsafe.Pointer in the same expression
+		target, ok := pass.TypesInfo.Types[parent.Fun]
+		if ok && target.IsType() && resolve.IsUnsafePointerType(target.Type) {
+			return PointerArithmetic
+		}
+		// passed directly to a system call
+		if !arithmetic && isSyscall(parent, pass) && child != parent.Fun {
+			return SyscallArgument
+		}
+	case *ast.AssignStmt:
+		// assigned to the Data field of a reflect header
+		for j, rhs := range parent.Rhs {
+			if rhs == child && j < len(parent.Lhs) && isHeaderData(parent.Lhs[j], pass) {
+				return ReflectHeaderData
+			}
+		}
+	case *ast.KeyValueExpr:
+		// the Data field in a reflect header composite literal
+		if ident, ok := parent.Key.(*ast.Ident); ok && ident.Name == "Data" && i > 0 {
+			if literal, ok := stack[i-1].(*ast.CompositeLit); ok {
+				if t := pass.TypesInfo.TypeOf(literal); t != nil && resolve.IsReflectHeader(t) {
+					return ReflectHeaderData
+				}
+			}
+		}
+	}
+	return PointerToUintptr
+}
+
+/**
+ * finds the pattern that a uintptr converted to unsafe.Pointer follows, or Invalid if it follows none
+ */
+func uintptrSourcePattern(expr ast.Expr, pass *analysis.Pass) Pattern {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.BinaryExpr:
+		// arithmetic is valid if one of the operands is a valid source of a pointer
+		if !isArithmetic(e.Op) {
+			return Invalid
+		}
+		if pattern := uintptrSourcePattern(e.X, pass); pattern != Invalid {
+			return pattern
+		}
+		return uintptrSourcePattern(e.Y, pass)
+	case *ast.CallExpr:
+		// a uintptr converted from a pointer in the same expression
+		if isPointerToUintptr(e, pass) {
+			return PointerArithmetic
+		}
+		// the result of reflect.Value.Pointer or reflect.Value.UnsafeAddr
+		if isReflectValuePointer(e, pass) {
+			return ReflectValuePointer
+		}
+	case *ast.SelectorExpr:
+		// the Data field of a reflect header
+		if isHeaderData(e, pass) {
+			return ReflectHeaderData
+		}
+	}
+	return Invalid
+}
+
+/**
+ * explains why a conversion of a uintptr to unsafe.Pointer is invalid
+ */
+func invalidConversionMessage(expr ast.Expr, values map[types.Object][]ast.Expr, pass *analysis.Pass) string {
+	variable := baseVariable(expr, pass)
+	if variable == nil {
+		return "converting an arbitrary uintptr value to unsafe.Pointer is invalid"
+	}
+
+	// variables holding the result of reflect.Value.Pointer or reflect.Value.UnsafeAddr
+	for _, value := range values[variable] {
+		if call, ok := ast.Unparen(value).(*ast.CallExpr); ok && isReflectValuePointer(call, pass) {
+			return "the result of reflect.Value." + calleeName(call, pass) + " must be converted to unsafe.Pointer " +
+				"immediately, not stored in a variable first"
+		}
+	}
+	return "converting a uintptr stored in variable " + variable.Name() + " to unsafe.Pointer is invalid, because " +
+		"the object it points to may have been moved or freed"
+}
+
+/**
+ * returns the first variable that pointer arithmetic is based on, or nil if there is none
+ */
+func baseVariable(expr ast.Expr, pass *analysis.Pass) *types.Var {
+	if binary, ok := ast.Unparen(expr).(*ast.BinaryExpr); ok && isArithmetic(binary.Op) {
+		if variable := baseVariable(binary.X, pass); variable != nil {
+			return variable
+		}
+		return baseVariable(binary.Y, pass)
+	}
+	return variableOf(expr, pass)
+}
+
+/**
+ * checks whether pointer arithmetic on the address of a variable with constant offsets stays within the variable
+ */
+func checkArithmeticBounds(call *ast.CallExpr, pass *analysis.Pass) {
+	// collect the base pointer and the sum of the constant offsets
+	var base *ast.CallExpr
+	var offset int64
+	var collect func(expr ast.Expr, sign int64) bool
+	collect = func(expr ast.Expr, sign int64) bool {
+		expr = ast.Unparen(expr)
+		if value := pass.TypesInfo.Types[expr].Value; value != nil {
+			v, exact := constant.Int64Val(constant.ToInt(value))
+			offset += sign * v
+			return exact
+		}
+		if conversion, ok := expr.(*ast.CallExpr); ok && isPointerToUintptr(conversion, pass) && base == nil &&
+			sign > 0 {
+			base = conversion
+			return true
+		}
+		binary, ok := expr.(*ast.BinaryExpr)
+		if !ok || (binary.Op != token.ADD && binary.Op != token.SUB) {
+			return false
+		}
+		ySign := sign
+		if binary.Op == token.SUB {
+			ySign = -sign
+		}
+		return collect(binary.X, sign) && collect(binary.Y, ySign)
+	}
+	if !collect(call.Args[0], 1) || base == nil {
+		return
+	}
+
+	// the base must be the address of a variable, like unsafe.Pointer(&x), so that the size of the allocation is known.
+	// Pointers stored in variables point to allocations of unknown size
+	conversion, ok := ast.Unparen(base.Args[0]).(*ast.CallExpr)
+	if !ok || len(conversion.Args) != 1 {
+		return
+	}
+	unary, ok := ast.Unparen(conversion.Args[0]).(*ast.UnaryExpr)
+	if !ok || unary.Op != token.AND {
+		return
+	}
+	variable := variableOf(unary.X, pass)
+	if variable == nil {
+		return
+	}
+	// the size of type parameters is only known once they are instantiated
+	if resolve.ContainsTypeParam(variable.Type()) {
+		return
+	}
+	size := pass.TypesSizes.Sizeof(variable.Type())
+	if offset < 0 || offset >= size {
+		pass.Reportf(call.Pos(), "pointer arithmetic moves outside the allocation of %s: offset %d is not within its "+
+			"%d bytes", variable.Name(), offset, size)
+	}
+}
+
+/**
+ * collects the values assigned to uintptr variables in the package
+ */
+func uintptrValues(inspectResult *inspector.Inspector, pass *analysis.Pass) map[types.Object][]ast.Expr {
+	values := make(map[types.Object][]ast.Expr)
+	record := func(lhs ast.Expr, rhs ast.Expr) {
+		if variable := variableOf(lhs, pass); variable != nil && isUintptr(variable.Type()) {
+			values[variable] = append(values[variable], rhs)
+		}
+	}
+	inspectResult.Preorder([]ast.Node{(*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil)}, func(n ast.Node) {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			if len(node.Lhs) == len(node.Rhs) {
+				for i := range node.Lhs {
+					record(node.Lhs[i], node.Rhs[i])
+				}
+			}
+		case *ast.ValueSpec:
+			if len(node.Names) == len(node.Values) {
+				for i := range node.Names {
+					record(node.Names[i], node.Values[i])
+				}
+			}
+		}
+	})
+	return values
+}
+
+/**
+ * checks whether any of the values satisfies a condition
+ */
+func anyValue(values []ast.Expr, condition func(ast.Expr) bool) bool {
+	for _, value := range values {
+		if condition(value) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * returns the variable that an identifier refers to, or nil if the expression is not an identifier of a variable
+ */
+func variableOf(expr ast.Expr, pass *analysis.Pass) *types.Var {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	variable, _ := pass.TypesInfo.ObjectOf(ident).(*types.Var)
+	return variable
+}
+
+/**
+ * checks whether an expression is a conversion of an unsafe.Pointer to uintptr
+ */
+func isPointerToUintptr(expr ast.Expr, pass *analysis.Pass) bool {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return false
+	}
+	target, ok := pass.TypesInfo.Types[call.Fun]
+	source := pass.TypesInfo.TypeOf(call.Args[0])
+	return ok && target.IsType() && isUintptr(target.Type) && source != nil && resolve.IsUnsafePointerType(source)
+}
+
+/**
+ * checks whether a call is a call to reflect.Value.Pointer or reflect.Value.UnsafeAddr
+ */
+func isReflectValuePointer(call *ast.CallExpr, pass *analysis.Pass) bool {
+	callee, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok {
+		return false
+	}
+	name := callee.FullName()
+	return name == "(reflect.Value).Pointer" || name == "(reflect.Value).UnsafeAddr"
+}
+
+/**
+ * checks whether a call is a system call that takes uintptr arguments, like syscall.Syscall
+ */
+func isSyscall(call *ast.CallExpr, pass *analysis.Pass) bool {
+	callee, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || callee.Pkg() == nil {
+		return false
+	}
+	switch callee.Pkg().Path() {
+	case "syscall", "golang.org/x/sys/unix", "golang.org/x/sys/windows":
+	default:
+		return false
+	}
+	name := callee.Name()
+	recv := callee.Type().(*types.Signature).Recv()
+	return (recv == nil && (strings.HasPrefix(name, "Syscall") || strings.HasPrefix(name, "RawSyscall"))) ||
+		(recv != nil && name == "Call")
+}
+
+/**
+ * returns the name of the function that a call expression calls
+ */
+func calleeName(call *ast.CallExpr, pass *analysis.Pass) string {
+	if callee := typeutil.Callee(pass.TypesInfo, call); callee != nil {
+		return callee.Name()
+	}
+	return "the function"
+}
+
+/**
+ * checks whether an expression selects the Data field of a reflect header, or of a struct with the same fields
+ */
+func isHeaderData(expr ast.Expr, pass *analysis.Pass) bool {
+	selector, ok := ast.Unparen(expr).(*ast.SelectorExpr)
+	if !ok || selector.Sel.Name != "Data" {
+		return false
+	}
+	t := pass.TypesInfo.TypeOf(selector.X)
+	return t != nil && resolve.IsReflectHeader(t)
+}
+
+/**
+ * checks whether an operator is used for pointer arithmetic
+ */
+func isArithmetic(op token.Token) bool {
+	switch op {
+	case token.ADD, token.SUB, token.MUL, token.QUO, token.REM, token.AND, token.OR, token.XOR, token.AND_NOT:
+		return true
+	}
+	return false
+}
+
+/**
+ * checks whether a type is uintptr
+ */
+func isUintptr(t types.Type) bool {
+	basic, ok := t.Underlying().(*types.Basic)
+	return ok && basic.Kind() == types.Uintptr
+}
+
+/**
+ * checks whether a type is a pointer type other than unsafe.Pointer
+ */
+func isPointer(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Pointer)
+	return ok
+}
@@ -0,0 +1,41 @@
+package unsafepointer_test
+
+import (
+	"github.com/jlauinger/go-safer/passes/unsafepointer"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"testing"
+)
+
+func Test(t *testing.T) {
+	// use go vet infrastructure testing and supply annotated code examples
+	testdata := analysistest.TestData()
+	testPackages := []string{
+		"bad/stored_uintptr",
+		"bad/reflect_value",
+		"bad/outside_allocation",
+		"bad/syscall_argument",
+
+		"good/documented_patterns",
+	}
+	analysistest.Run(t, testdata, unsafepointer.Analyzer, testPackages...)
+}
+
+func TestPatterns(t *testing.T) {
+	// every documented pattern is found in the example package, and no conversion is classified invalid
+	testdata := analysistest.TestData()
+	results := analysistest.Run(t, testdata, unsafepointer.Analyzer, "good/documented_patterns")
+	conversions := results[0].Result.(unsafepointer.Conversions)
+
+	found := make(map[unsafepointer.Pattern]int)
+	for _, pattern := range conversions {
+		found[pattern]++
+	}
+	if found[unsafepointer.Invalid] != 0 {
+		t.Errorf("found %d invalid conversions", found[unsafepointer.Invalid])
+	}
+	for pattern := unsafepointer.PointerToPointer; pattern <= unsafepointer.ReflectHeaderData; pattern++ {
+		if found[pattern] == 0 {
+			t.Errorf("found no conversion following pattern %d", pattern)
+		}
+	}
+}